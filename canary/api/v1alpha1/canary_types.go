@@ -17,13 +17,17 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // CanarySpec defines the desired state of Canary
+// +kubebuilder:validation:XValidation:rule="has(self.cronSchedule) || has(self.stepInterval)",message="either cronSchedule or stepInterval is required"
 type CanarySpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -40,17 +44,299 @@ type CanarySpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	TotalReplicas int32 `json:"totalReplicas"`
 
+	// DynamicTotal defines the mode recomputing the total replicas instead of using fixed totalReplicas.
+	// The step ratio is preserved instead of absolute replica counts.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	DynamicTotal *DynamicTotalSpec `json:"dynamicTotal,omitempty"`
+
 	// StepReplicas defines the number of replicas to scale up/down in each step
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Minimum=1
 	StepReplicas int32 `json:"stepReplicas"`
 
-	// CronSchedule defines the cron schedule to run the canary
+	// CronSchedule defines the cron schedule to run the canary.
+	// Ignored if stepInterval is set.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	CronSchedule string `json:"cronSchedule,omitempty"`
+
+	// StepInterval defines the duration to wait before advancing to the next step,
+	// measured from the moment the current step became healthy. If set, it is used instead of cronSchedule.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	StepInterval *metav1.Duration `json:"stepInterval,omitempty"`
+
+	// AutoStart defines whether to start the canary immediately upon creation without the apply command
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	AutoStart bool `json:"autoStart,omitempty"`
+
+	// StartAt defines the time to start the canary without the apply command
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	StartAt *metav1.Time `json:"startAt,omitempty"`
+
+	// ProgressDeadline defines the maximum duration for the current step to become healthy after it started.
+	// If exceeded, the canary is degraded.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+
+	// MaxDuration defines the maximum duration of the whole run from the start to the completion.
+	// If exceeded, the canary is degraded.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+
+	// RollbackOnDeadline defines whether to rollback the canary when progressDeadline or maxDuration is exceeded
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	RollbackOnDeadline bool `json:"rollbackOnDeadline,omitempty"`
+
+	// AllowedWindows defines the time windows in which the canary can advance to the next step.
+	// If empty, the canary can advance at any time outside of blackoutWindows.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	AllowedWindows []TimeWindow `json:"allowedWindows,omitempty"`
+
+	// BlackoutWindows defines the time windows in which the canary does not advance to the next step
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	BlackoutWindows []TimeWindow `json:"blackoutWindows,omitempty"`
+
+	// Holidays defines a shared ConfigMap of holidays on which the canary does not advance to the next step
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	CronSchedule string `json:"cronSchedule"`
+	// +optional
+	Holidays *HolidaysSpec `json:"holidays,omitempty"`
 
 	// EnableRollback defines whether to enable rollback or not
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	EnableRollback bool `json:"enableRollback"`
+
+	// EnableHPA defines whether to coordinate the HorizontalPodAutoscalers of both deployments.
+	// If enabled, minReplicas and maxReplicas of the old deployment's HorizontalPodAutoscaler are split between
+	// both HorizontalPodAutoscalers by the step ratio instead of fixed totalReplicas,
+	// and the original configuration is restored on completion or rollback.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	EnableHPA bool `json:"enableHPA,omitempty"`
+
+	// DriftPolicy defines how to react when the deployments are changed outside of the canary, revert, pause or adopt.
+	// revert restores the replicas of the current step, pause stops the canary until it is applied again,
+	// adopt keeps the changed replicas as the new total replicas.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=revert
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// TemplateChangePolicy defines how to react when the pod template of the new deployment is changed mid-rollout,
+	// restart from step 0, pause until it is applied again or continue.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:default=continue
+	// +optional
+	TemplateChangePolicy TemplateChangePolicy `json:"templateChangePolicy,omitempty"`
+
+	// Notifications defines the webhooks to notify on canary lifecycle events
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Notifications *NotificationSpec `json:"notifications,omitempty"`
+
+	// Rollback defines how replicas are returned to the old deployment on rollback
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Rollback *RollbackSpec `json:"rollback,omitempty"`
+
+	// Scaling defines the surge-then-shrink policy applied to each step.
+	// If empty, both deployments are scaled at the same time.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Scaling *ScalingSpec `json:"scaling,omitempty"`
+
+	// PodDisruptionBudget defines a PodDisruptionBudget spanning both deployments managed for the canary's lifetime
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// TimeWindow defines a window starting at every time of the cron schedule and lasting for the duration.
+type TimeWindow struct {
+	// Name defines the name of the window shown in the status
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Schedule defines the cron expression of the window start, e.g. "0 9 * * 1-5"
+	Schedule string `json:"schedule"`
+
+	// Duration defines how long the window lasts from the start, e.g. "8h"
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone defines the IANA time zone of the schedule, e.g. "Asia/Seoul". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// HolidaysSpec defines a ConfigMap listing holidays.
+// Every value of the ConfigMap holds dates in the YYYY-MM-DD format separated by new lines.
+type HolidaysSpec struct {
+	// ConfigMapRef defines the ConfigMap holding the holidays
+	ConfigMapRef ConfigMapReference `json:"configMapRef"`
+
+	// TimeZone defines the IANA time zone in which the holidays are observed. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ConfigMapReference defines a reference to a ConfigMap in the namespace of the canary or the operator.
+type ConfigMapReference struct {
+	// Name defines the name of the ConfigMap
+	Name string `json:"name"`
+
+	// Namespace defines the namespace of the ConfigMap, either the namespace of the canary or the operator.
+	// Defaults to the namespace of the canary.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget managed by the canary.
+// It selects the pod labels shared by both deployments and is deleted when the canary is complete.
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable defines the number or percentage of pods of both deployments that must be available
+	// +kubebuilder:validation:XIntOrString
+	MinAvailable intstr.IntOrString `json:"minAvailable"`
+}
+
+// ScalingSpec defines how both deployments are scaled in each step.
+// The growing deployment is scaled up first within maxSurge and the shrinking deployment is scaled down
+// only while the available replicas of both deployments stay above totalReplicas - maxUnavailable.
+type ScalingSpec struct {
+	// MaxSurge defines the number or percentage of totalReplicas that can be scheduled above totalReplicas.
+	// Defaults to stepReplicas.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable defines the number or percentage of totalReplicas that can be unavailable. Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// DriftPolicy defines how to react to out-of-band changes of the deployments
+// +kubebuilder:validation:Enum=revert;pause;adopt
+type DriftPolicy string
+
+const (
+	DriftRevert DriftPolicy = "revert"
+	DriftPause  DriftPolicy = "pause"
+	DriftAdopt  DriftPolicy = "adopt"
+)
+
+// TemplateChangePolicy defines how to react to pod template changes of the new deployment
+// +kubebuilder:validation:Enum=restart;pause;continue
+type TemplateChangePolicy string
+
+const (
+	TemplateChangeRestart  TemplateChangePolicy = "restart"
+	TemplateChangePause    TemplateChangePolicy = "pause"
+	TemplateChangeContinue TemplateChangePolicy = "continue"
+)
+
+// DynamicTotalSource defines where the total replicas are read from
+// +kubebuilder:validation:Enum=observed;configMap
+type DynamicTotalSource string
+
+const (
+	// DynamicTotalObserved follows the combined replicas of both deployments, including manual scaling
+	DynamicTotalObserved DynamicTotalSource = "observed"
+	// DynamicTotalConfigMap reads the total replicas from a ConfigMap key
+	DynamicTotalConfigMap DynamicTotalSource = "configMap"
+)
+
+// DynamicTotalSpec defines the source of the total replicas
+type DynamicTotalSpec struct {
+	// Source defines where the total replicas are read from, observed or configMap
+	// +kubebuilder:default=observed
+	// +optional
+	Source DynamicTotalSource `json:"source,omitempty"`
+
+	// ConfigMapKeyRef defines the ConfigMap key in the same namespace holding the total replicas for the configMap source
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// RollbackStrategy defines how replicas are returned to the old deployment
+// +kubebuilder:validation:Enum=immediate;stepped
+type RollbackStrategy string
+
+const (
+	// RollbackImmediate returns all replicas to the old deployment in one reconcile
+	RollbackImmediate RollbackStrategy = "immediate"
+	// RollbackStepped returns replicas to the old deployment one step per interval
+	RollbackStepped RollbackStrategy = "stepped"
+)
+
+// RollbackSpec defines the rollback strategy
+type RollbackSpec struct {
+	// Strategy defines the rollback strategy, immediate or stepped
+	// +kubebuilder:default=immediate
+	// +optional
+	Strategy RollbackStrategy `json:"strategy,omitempty"`
+
+	// Interval defines the interval between the steps of the stepped rollback. Defaults to 30s.
+	// A step is taken only when the old deployment is fully available.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// CanaryPhase defines the phase of the canary
+// +kubebuilder:validation:Enum=Pending;Progressing;Paused;Promoting;Completed;RollingBack;RolledBack;Failed
+type CanaryPhase string
+
+const (
+	// PhasePending waits for the apply command, autoStart or startAt
+	PhasePending CanaryPhase = "Pending"
+	// PhaseProgressing runs the canary with the replicas of the current step available
+	PhaseProgressing CanaryPhase = "Progressing"
+	// PhasePaused stops the canary by the stop command, drift, template change or deadline
+	PhasePaused CanaryPhase = "Paused"
+	// PhasePromoting runs the canary while the replicas are scaling to the current step
+	PhasePromoting CanaryPhase = "Promoting"
+	// PhaseCompleted moves all replicas to the new deployment
+	PhaseCompleted CanaryPhase = "Completed"
+	// PhaseRollingBack returns replicas to the old deployment one step per interval
+	PhaseRollingBack CanaryPhase = "RollingBack"
+	// PhaseRolledBack returns all replicas to the old deployment
+	PhaseRolledBack CanaryPhase = "RolledBack"
+	// PhaseFailed cannot run the canary because the deployments are not found
+	PhaseFailed CanaryPhase = "Failed"
+)
+
+// NotificationEvent defines a canary lifecycle event to notify
+// +kubebuilder:validation:Enum=started;paused;completed;rolledback
+type NotificationEvent string
+
+const (
+	NotificationStarted    NotificationEvent = "started"
+	NotificationPaused     NotificationEvent = "paused"
+	NotificationCompleted  NotificationEvent = "completed"
+	NotificationRolledBack NotificationEvent = "rolledback"
+)
+
+// NotificationSpec defines the Slack/Microsoft Teams compatible notifications
+type NotificationSpec struct {
+	// SecretRef defines the name of the Secret in the same namespace holding incoming-webhook URLs.
+	// Every value of the Secret is used as a webhook URL.
+	SecretRef string `json:"secretRef"`
+
+	// Events defines the events to notify. All events are notified if empty.
+	// +optional
+	Events []NotificationEvent `json:"events,omitempty"`
+
+	// Templates defines the Go template of the message per event.
+	// Available fields: .Event, .Namespace, .Name, .Step, .OldReplicas, .NewReplicas, .State, .Message
+	// +optional
+	Templates map[NotificationEvent]string `json:"templates,omitempty"`
 }
 
 // CanaryStatus defines the observed state of Canary
@@ -70,13 +356,78 @@ type CanaryStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	CurrentStep int32 `json:"currentStep"`
 
-	// State defines the current state of the canary
+	// State defines the current phase of the canary
 	// +operator-sdk:csv:customresourcedefinitions:type=status
-	State string `json:"state"`
+	// +optional
+	State CanaryPhase `json:"state,omitempty"`
 
 	// Message defines the state message of the canary
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	Message string `json:"message"`
+
+	// TraceID defines the trace id linking all spans of the current canary run
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	TraceID string `json:"traceID,omitempty"`
+
+	// SpanID defines the root span id of the current canary run
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	SpanID string `json:"spanID,omitempty"`
+
+	// TotalReplicas defines the total replicas recomputed by dynamicTotal
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	TotalReplicas int32 `json:"totalReplicas,omitempty"`
+
+	// OldObservedGeneration defines the generation of the old deployment last synced by the canary
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	OldObservedGeneration int64 `json:"oldObservedGeneration,omitempty"`
+
+	// NewObservedGeneration defines the generation of the new deployment last synced by the canary
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	NewObservedGeneration int64 `json:"newObservedGeneration,omitempty"`
+
+	// NewTemplateHash defines the hash of the pod template of the new deployment the canary is rolling out
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	NewTemplateHash string `json:"newTemplateHash,omitempty"`
+
+	// Conditions defines the latest observations of the canary
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// AppliedCommands defines the UIDs of the most recent CanaryCommands applied to the canary.
+	// A CanaryCommand listed here is not applied again.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	AppliedCommands []types.UID `json:"appliedCommands,omitempty"`
+
+	// LastFailedStep defines the step the canary was rolled back from, the retry command resumes from this step
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	LastFailedStep int32 `json:"lastFailedStep,omitempty"`
+
+	// StartTime defines the time the current run was started, autoStart and startAt are ignored once it is set
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// StepStartTime defines the time the current step was started
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+
+	// NextStepTime defines the time the canary is scheduled to advance to the next step.
+	// With stepInterval, it is set once the current step became healthy.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	NextStepTime *metav1.Time `json:"nextStepTime,omitempty"`
 }
 
 //+kubebuilder:printcolumn:name="OldReplicas",type="integer",JSONPath=".status.oldReplicas"
@@ -84,6 +435,7 @@ type CanaryStatus struct {
 //+kubebuilder:printcolumn:name="CurrentStep",type="integer",JSONPath=".status.currentStep"
 //+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
 //+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.message",priority=1
+//+kubebuilder:printcolumn:name="NextStep",type="date",JSONPath=".status.nextStepTime",priority=1
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
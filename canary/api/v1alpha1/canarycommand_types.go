@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CanaryCommandSpec defines the desired state of CanaryCommand
+type CanaryCommandSpec struct {
+	// CanaryName defines the name of the Canary in the same namespace to command
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:MinLength=1
+	CanaryName string `json:"canaryName"`
+
+	// Command defines the command to apply to the Canary
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Enum=apply;stop;rollback;completion;promote;skip;back;setstep;retry
+	Command string `json:"command"`
+
+	// Step defines the target step of the setstep command
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Step *int32 `json:"step,omitempty"`
+
+	// IssuedBy defines the user who issued the command. It is set by the admission webhook.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	IssuedBy string `json:"issuedBy,omitempty"`
+}
+
+// Commands supported by the CanaryCommand spec.command and the command annotation of a Canary
+const (
+	CommandApply      = "apply"
+	CommandStop       = "stop"
+	CommandRollback   = "rollback"
+	CommandCompletion = "completion"
+	CommandPromote    = "promote"
+	CommandSkip       = "skip"
+	CommandBack       = "back"
+	CommandSetStep    = "setstep"
+	CommandRetry      = "retry"
+)
+
+// CanaryCommandPhase defines the result of a CanaryCommand
+type CanaryCommandPhase string
+
+const (
+	CanaryCommandSucceeded CanaryCommandPhase = "Succeeded"
+	CanaryCommandRejected  CanaryCommandPhase = "Rejected"
+)
+
+// CanaryCommandStatus defines the observed state of CanaryCommand
+type CanaryCommandStatus struct {
+	// Phase defines the result of the command, empty until the command is processed
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Phase CanaryCommandPhase `json:"phase,omitempty"`
+
+	// Message defines the result message of the command
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ProcessedAt defines the time when the command was processed
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	ProcessedAt *metav1.Time `json:"processedAt,omitempty"`
+
+	// State defines the phase of the Canary after the command
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	State CanaryPhase `json:"state,omitempty"`
+
+	// CurrentStep defines the step of the Canary after the command
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	CurrentStep int32 `json:"currentStep,omitempty"`
+}
+
+//+kubebuilder:printcolumn:name="Canary",type="string",JSONPath=".spec.canaryName"
+//+kubebuilder:printcolumn:name="Command",type="string",JSONPath=".spec.command"
+//+kubebuilder:printcolumn:name="IssuedBy",type="string",JSONPath=".spec.issuedBy"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.message",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// CanaryCommand is the Schema for the canarycommands API
+type CanaryCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanaryCommandSpec   `json:"spec,omitempty"`
+	Status CanaryCommandStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CanaryCommandList contains a list of CanaryCommand
+type CanaryCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CanaryCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CanaryCommand{}, &CanaryCommandList{})
+}
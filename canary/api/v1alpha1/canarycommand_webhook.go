@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var canarycommandlog = logf.Log.WithName("canarycommand-resource")
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks
+func (r *CanaryCommand) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&canaryCommandDefaulter{}).
+		WithValidator(&canaryCommandValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-canary-k8shuginn-io-v1alpha1-canarycommand,mutating=true,failurePolicy=fail,sideEffects=None,groups=canary.k8shuginn.io,resources=canarycommands,verbs=create,versions=v1alpha1,name=mcanarycommand.kb.io,admissionReviewVersions=v1
+
+// canaryCommandDefaulter records the user who issued the command
+type canaryCommandDefaulter struct{}
+
+var _ admission.CustomDefaulter = &canaryCommandDefaulter{}
+
+// Default implements admission.CustomDefaulter so a webhook will be registered for the type
+func (d *canaryCommandDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	command, ok := obj.(*CanaryCommand)
+	if !ok {
+		return fmt.Errorf("expected a CanaryCommand but got a %T", obj)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// IssuedBy is always overwritten so it can not be forged by the requester
+	command.Spec.IssuedBy = req.UserInfo.Username
+	canarycommandlog.Info("default", "name", command.Name, "issuedBy", command.Spec.IssuedBy)
+
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-canary-k8shuginn-io-v1alpha1-canarycommand,mutating=false,failurePolicy=fail,sideEffects=None,groups=canary.k8shuginn.io,resources=canarycommands,verbs=create;update,versions=v1alpha1,name=vcanarycommand.kb.io,admissionReviewVersions=v1
+
+// canaryCommandValidator rejects invalid commands and changes to issued commands
+type canaryCommandValidator struct{}
+
+var _ admission.CustomValidator = &canaryCommandValidator{}
+
+// ValidateCreate implements admission.CustomValidator so a webhook will be registered for the type
+func (v *canaryCommandValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	command, ok := obj.(*CanaryCommand)
+	if !ok {
+		return nil, fmt.Errorf("expected a CanaryCommand but got a %T", obj)
+	}
+
+	return nil, command.validateSpec()
+}
+
+// ValidateUpdate implements admission.CustomValidator so a webhook will be registered for the type
+func (v *canaryCommandValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCommand, ok := oldObj.(*CanaryCommand)
+	if !ok {
+		return nil, fmt.Errorf("expected a CanaryCommand but got a %T", oldObj)
+	}
+	newCommand, ok := newObj.(*CanaryCommand)
+	if !ok {
+		return nil, fmt.Errorf("expected a CanaryCommand but got a %T", newObj)
+	}
+
+	if !equality.Semantic.DeepEqual(oldCommand.Spec, newCommand.Spec) {
+		return nil, fmt.Errorf("spec of CanaryCommand %s is immutable", newCommand.Name)
+	}
+
+	return nil, nil
+}
+
+// ValidateDelete implements admission.CustomValidator so a webhook will be registered for the type
+func (v *canaryCommandValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSpec checks the required fields, supported commands are validated by the CRD schema
+func (r *CanaryCommand) validateSpec() error {
+	if r.Spec.CanaryName == "" {
+		return fmt.Errorf("spec.canaryName is required")
+	}
+	if r.Spec.Command == "" {
+		return fmt.Errorf("spec.command is required")
+	}
+	if r.Spec.Command == CommandSetStep && r.Spec.Step == nil {
+		return fmt.Errorf("spec.step is required for the setstep command")
+	}
+	if r.Spec.Command != CommandSetStep && r.Spec.Step != nil {
+		return fmt.Errorf("spec.step is only allowed for the setstep command")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("CanaryCommand webhook", func() {
+	step := func(step int32) *int32 {
+		return &step
+	}
+
+	command := func(cmd string, step *int32) *CanaryCommand {
+		return &CanaryCommand{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "command"},
+			Spec:       CanaryCommandSpec{CanaryName: "canary-sample", Command: cmd, Step: step},
+		}
+	}
+
+	requestContext := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: username}},
+		})
+	}
+
+	Context("Default", func() {
+		It("should record the requesting user", func() {
+			obj := command(CommandApply, nil)
+			Expect((&canaryCommandDefaulter{}).Default(requestContext("alice"), obj)).To(Succeed())
+			Expect(obj.Spec.IssuedBy).To(Equal("alice"))
+		})
+
+		It("should overwrite a forged issuedBy", func() {
+			obj := command(CommandApply, nil)
+			obj.Spec.IssuedBy = "admin"
+			Expect((&canaryCommandDefaulter{}).Default(requestContext("alice"), obj)).To(Succeed())
+			Expect(obj.Spec.IssuedBy).To(Equal("alice"))
+		})
+
+		It("should fail without an admission request", func() {
+			Expect((&canaryCommandDefaulter{}).Default(context.Background(), command(CommandApply, nil))).NotTo(Succeed())
+		})
+
+		It("should reject other objects", func() {
+			Expect((&canaryCommandDefaulter{}).Default(requestContext("alice"), &Canary{})).
+				To(MatchError(ContainSubstring("expected a CanaryCommand")))
+		})
+	})
+
+	DescribeTable("ValidateCreate",
+		func(obj *CanaryCommand, message string) {
+			_, err := (&canaryCommandValidator{}).ValidateCreate(context.Background(), obj)
+			if message == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			}
+		},
+		Entry("command without step", command(CommandPromote, nil), ""),
+		Entry("setstep with step", command(CommandSetStep, step(2)), ""),
+		Entry("setstep to step 0", command(CommandSetStep, step(0)), ""),
+		Entry("setstep without step", command(CommandSetStep, nil), "spec.step is required for the setstep command"),
+		Entry("step on another command", command(CommandSkip, step(2)), "spec.step is only allowed for the setstep command"),
+		Entry("missing canary name", &CanaryCommand{Spec: CanaryCommandSpec{Command: CommandApply}}, "spec.canaryName is required"),
+		Entry("missing command", &CanaryCommand{Spec: CanaryCommandSpec{CanaryName: "canary-sample"}}, "spec.command is required"),
+	)
+
+	Context("ValidateUpdate", func() {
+		It("should allow status and metadata changes", func() {
+			oldObj := command(CommandApply, nil)
+			newObj := oldObj.DeepCopy()
+			newObj.Labels = map[string]string{"team": "platform"}
+			newObj.Status.Phase = CanaryCommandSucceeded
+			_, err := (&canaryCommandValidator{}).ValidateUpdate(context.Background(), oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("should reject spec changes",
+			func(mutate func(spec *CanaryCommandSpec)) {
+				oldObj := command(CommandSetStep, step(2))
+				newObj := oldObj.DeepCopy()
+				mutate(&newObj.Spec)
+				_, err := (&canaryCommandValidator{}).ValidateUpdate(context.Background(), oldObj, newObj)
+				Expect(err).To(MatchError(ContainSubstring("spec of CanaryCommand command is immutable")))
+			},
+			Entry("command", func(spec *CanaryCommandSpec) { spec.Command = CommandRollback }),
+			Entry("step", func(spec *CanaryCommandSpec) { spec.Step = step(3) }),
+			Entry("canary name", func(spec *CanaryCommandSpec) { spec.CanaryName = "other" }),
+			Entry("issuedBy", func(spec *CanaryCommandSpec) { spec.IssuedBy = "admin" }),
+		)
+	})
+})
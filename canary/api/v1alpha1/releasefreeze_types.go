@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReleaseFreezeSpec defines the desired state of ReleaseFreeze
+type ReleaseFreezeSpec struct {
+	// NamespaceSelector defines the labels of the namespaces whose canaries are frozen.
+	// If empty, canaries in all namespaces are frozen.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Selector defines the labels of the canaries to freeze. If empty, all canaries are frozen.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExpiresAt defines the time the freeze is lifted. If empty, the freeze lasts until it is deleted.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Reason defines why the release is frozen, shown in the status and events of the frozen canaries
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReleaseFreezeStatus defines the observed state of ReleaseFreeze
+type ReleaseFreezeStatus struct {
+	// Active defines whether the freeze is in effect
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Active bool `json:"active,omitempty"`
+
+	// FrozenCanaries defines the namespace/name of the canaries paused by the freeze
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	FrozenCanaries []string `json:"frozenCanaries,omitempty"`
+}
+
+//+kubebuilder:printcolumn:name="Active",type="boolean",JSONPath=".status.active"
+//+kubebuilder:printcolumn:name="ExpiresAt",type="string",JSONPath=".spec.expiresAt"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.reason",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// ReleaseFreeze is the Schema for the releasefreezes API.
+// It pauses the step advancement of the matching canaries until it expires or is deleted.
+type ReleaseFreeze struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReleaseFreezeSpec   `json:"spec,omitempty"`
+	Status ReleaseFreezeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReleaseFreezeList contains a list of ReleaseFreeze
+type ReleaseFreezeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReleaseFreeze `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReleaseFreeze{}, &ReleaseFreezeList{})
+}
@@ -0,0 +1,14 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "API Suite")
+}
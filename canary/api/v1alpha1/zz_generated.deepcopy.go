@@ -21,7 +21,11 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -29,8 +33,8 @@ func (in *Canary) DeepCopyInto(out *Canary) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Canary.
@@ -51,6 +55,104 @@ func (in *Canary) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCommand) DeepCopyInto(out *CanaryCommand) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCommand.
+func (in *CanaryCommand) DeepCopy() *CanaryCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryCommand) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCommandList) DeepCopyInto(out *CanaryCommandList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanaryCommand, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCommandList.
+func (in *CanaryCommandList) DeepCopy() *CanaryCommandList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCommandList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanaryCommandList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCommandSpec) DeepCopyInto(out *CanaryCommandSpec) {
+	*out = *in
+	if in.Step != nil {
+		in, out := &in.Step, &out.Step
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCommandSpec.
+func (in *CanaryCommandSpec) DeepCopy() *CanaryCommandSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCommandSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryCommandStatus) DeepCopyInto(out *CanaryCommandStatus) {
+	*out = *in
+	if in.ProcessedAt != nil {
+		in, out := &in.ProcessedAt, &out.ProcessedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryCommandStatus.
+func (in *CanaryCommandStatus) DeepCopy() *CanaryCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryList) DeepCopyInto(out *CanaryList) {
 	*out = *in
@@ -86,6 +188,65 @@ func (in *CanaryList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
 	*out = *in
+	if in.DynamicTotal != nil {
+		in, out := &in.DynamicTotal, &out.DynamicTotal
+		*out = new(DynamicTotalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StepInterval != nil {
+		in, out := &in.StepInterval, &out.StepInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StartAt != nil {
+		in, out := &in.StartAt, &out.StartAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AllowedWindows != nil {
+		in, out := &in.AllowedWindows, &out.AllowedWindows
+		*out = make([]TimeWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlackoutWindows != nil {
+		in, out := &in.BlackoutWindows, &out.BlackoutWindows
+		*out = make([]TimeWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Holidays != nil {
+		in, out := &in.Holidays, &out.Holidays
+		*out = new(HolidaysSpec)
+		**out = **in
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(ScalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
@@ -101,6 +262,30 @@ func (in *CanarySpec) DeepCopy() *CanarySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedCommands != nil {
+		in, out := &in.AppliedCommands, &out.AppliedCommands
+		*out = make([]types.UID, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StepStartTime != nil {
+		in, out := &in.StepStartTime, &out.StepStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextStepTime != nil {
+		in, out := &in.NextStepTime, &out.NextStepTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
@@ -112,3 +297,266 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicTotalSpec) DeepCopyInto(out *DynamicTotalSpec) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicTotalSpec.
+func (in *DynamicTotalSpec) DeepCopy() *DynamicTotalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicTotalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HolidaysSpec) DeepCopyInto(out *HolidaysSpec) {
+	*out = *in
+	out.ConfigMapRef = in.ConfigMapRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HolidaysSpec.
+func (in *HolidaysSpec) DeepCopy() *HolidaysSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HolidaysSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSpec) DeepCopyInto(out *NotificationSpec) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[NotificationEvent]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSpec.
+func (in *NotificationSpec) DeepCopy() *NotificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	out.MinAvailable = in.MinAvailable
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseFreeze) DeepCopyInto(out *ReleaseFreeze) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseFreeze.
+func (in *ReleaseFreeze) DeepCopy() *ReleaseFreeze {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseFreeze)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReleaseFreeze) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseFreezeList) DeepCopyInto(out *ReleaseFreezeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ReleaseFreeze, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseFreezeList.
+func (in *ReleaseFreezeList) DeepCopy() *ReleaseFreezeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseFreezeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReleaseFreezeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseFreezeSpec) DeepCopyInto(out *ReleaseFreezeSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseFreezeSpec.
+func (in *ReleaseFreezeSpec) DeepCopy() *ReleaseFreezeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseFreezeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseFreezeStatus) DeepCopyInto(out *ReleaseFreezeStatus) {
+	*out = *in
+	if in.FrozenCanaries != nil {
+		in, out := &in.FrozenCanaries, &out.FrozenCanaries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseFreezeStatus.
+func (in *ReleaseFreezeStatus) DeepCopy() *ReleaseFreezeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseFreezeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackSpec) DeepCopyInto(out *RollbackSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackSpec.
+func (in *RollbackSpec) DeepCopy() *RollbackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingSpec) DeepCopyInto(out *ScalingSpec) {
+	*out = *in
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingSpec.
+func (in *ScalingSpec) DeepCopy() *ScalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeWindow.
+func (in *TimeWindow) DeepCopy() *TimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// status Canary의 현재 단계, replicas, pod 상태를 출력합니다.
+func status(ctx context.Context, out io.Writer, c client.Client, namespace, name string) error {
+	s, err := render(ctx, c, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, s)
+	return err
+}
+
+// watch interval마다 Canary 상태를 조회하고 변경되었을 때 출력합니다.
+func watch(ctx context.Context, out io.Writer, c client.Client, namespace, name string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		s, err := render(ctx, c, namespace, name)
+		if err != nil {
+			return err
+		}
+		if s != last {
+			fmt.Fprintf(out, "--- %s\n%s", time.Now().Format(time.RFC3339), s)
+			last = s
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// history Canary에 발행된 CanaryCommand를 생성 순서대로 출력합니다.
+func history(ctx context.Context, out io.Writer, c client.Client, namespace, name string) error {
+	list := &canaryv1alpha1.CanaryCommandList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	commands := make([]canaryv1alpha1.CanaryCommand, 0, len(list.Items))
+	for _, command := range list.Items {
+		if command.Spec.CanaryName == name {
+			commands = append(commands, command)
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].CreationTimestamp.Before(&commands[j].CreationTimestamp)
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tNAME\tCOMMAND\tISSUED BY\tPHASE\tSTATE\tSTEP\tMESSAGE")
+	for _, command := range commands {
+		phase := string(command.Status.Phase)
+		if phase == "" {
+			phase = "Pending"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			command.CreationTimestamp.Format(time.RFC3339), command.Name, command.Spec.Command,
+			command.Spec.IssuedBy, phase, command.Status.State, command.Status.CurrentStep, command.Status.Message)
+	}
+
+	return w.Flush()
+}
+
+// issueCommand CanaryCommand를 생성하고 처리 결과를 기다립니다. step은 setstep Command에만 사용됩니다.
+func issueCommand(ctx context.Context, out io.Writer, c client.Client, namespace, name, cmd string, step *int32, timeout time.Duration) error {
+	canary := &canaryv1alpha1.Canary{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, canary); err != nil {
+		return err
+	}
+
+	command := &canaryv1alpha1.CanaryCommand{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, GenerateName: fmt.Sprintf("%s-%s-", name, cmd)},
+		Spec:       canaryv1alpha1.CanaryCommandSpec{CanaryName: name, Command: cmd, Step: step},
+	}
+	if err := c.Create(ctx, command); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "canarycommand/%s created\n", command.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for command.Status.Phase == "" {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("canarycommand/%s is not processed in %s", command.Name, timeout)
+		case <-ticker.C:
+		}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(command), command); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "canarycommand/%s %s: %s\n", command.Name, command.Status.Phase, command.Status.Message)
+	if command.Status.Phase == canaryv1alpha1.CanaryCommandRejected {
+		return fmt.Errorf("command %q is rejected", cmd)
+	}
+
+	return nil
+}
+
+// render Canary와 old, new Deployment 상태를 문자열로 만듭니다.
+func render(ctx context.Context, c client.Client, namespace, name string) (string, error) {
+	canary := &canaryv1alpha1.Canary{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, canary); err != nil {
+		return "", err
+	}
+
+	maxStep := int32(0)
+	if canary.Spec.StepReplicas > 0 {
+		maxStep = canary.Spec.TotalReplicas / canary.Spec.StepReplicas
+	}
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s/%s\n", canary.Namespace, canary.Name)
+	fmt.Fprintf(w, "State:\t%s\n", canary.Status.State)
+	fmt.Fprintf(w, "Step:\t%d/%d\n", canary.Status.CurrentStep, maxStep)
+	total := canary.Spec.TotalReplicas
+	if canary.Status.TotalReplicas > 0 {
+		total = canary.Status.TotalReplicas
+	}
+	fmt.Fprintf(w, "Replicas:\told %d, new %d (total %d)\n", canary.Status.OldReplicas, canary.Status.NewReplicas, total)
+	fmt.Fprintf(w, "Message:\t%s\n", canary.Status.Message)
+	for _, condition := range canary.Status.Conditions {
+		if condition.Status == metav1.ConditionTrue {
+			fmt.Fprintf(w, "%s:\t%s (%s)\n", condition.Type, condition.Message, condition.Reason)
+		}
+	}
+	if canary.Status.NextStepTime != nil {
+		fmt.Fprintf(w, "Next step:\t%s\n", canary.Status.NextStepTime.Format(time.RFC3339))
+	}
+	if canary.Status.LastFailedStep > 0 {
+		fmt.Fprintf(w, "Last failed step:\t%d\n", canary.Status.LastFailedStep)
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPLOYMENT\tNAME\tDESIRED\tREADY\tAVAILABLE\tPODS\tRESTARTS")
+	for _, d := range []struct{ role, name string }{
+		{"old", canary.Spec.OldDeployment},
+		{"new", canary.Spec.NewDeployment},
+	} {
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: d.name}, deploy); err != nil {
+			if apierrors.IsNotFound(err) {
+				fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\n", d.role, d.name)
+				continue
+			}
+			return "", err
+		}
+
+		pods, restarts, err := podHealth(ctx, c, deploy)
+		if err != nil {
+			return "", err
+		}
+		desired := int32(0)
+		if deploy.Spec.Replicas != nil {
+			desired = *deploy.Spec.Replicas
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n", d.role, d.name, desired,
+			deploy.Status.ReadyReplicas, deploy.Status.AvailableReplicas, pods, restarts)
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// podHealth Deployment pod 수와 container 재시작 횟수 합계를 반환합니다.
+func podHealth(ctx context.Context, c client.Client, deploy *appsv1.Deployment) (int, int32, error) {
+	if deploy.Spec.Selector == nil {
+		return 0, 0, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(deploy.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, 0, err
+	}
+
+	var restarts int32
+	for _, pod := range podList.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			restarts += container.RestartCount
+		}
+	}
+
+	return len(podList.Items), restarts, nil
+}
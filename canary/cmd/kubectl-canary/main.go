@@ -0,0 +1,237 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-canary is a kubectl plugin to inspect and control Canary resources.
+// Install the binary in PATH and run it as `kubectl canary <command> <name>`.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(canaryv1alpha1.AddToScheme(scheme))
+}
+
+// commandAliases plugin subcommand와 Canary Command의 매핑입니다.
+var commandAliases = map[string]string{
+	"start":    canaryv1alpha1.CommandApply,
+	"pause":    canaryv1alpha1.CommandStop,
+	"promote":  canaryv1alpha1.CommandPromote,
+	"skip":     canaryv1alpha1.CommandSkip,
+	"back":     canaryv1alpha1.CommandBack,
+	"abort":    canaryv1alpha1.CommandRollback,
+	"rollback": canaryv1alpha1.CommandRollback,
+	"retry":    canaryv1alpha1.CommandRetry,
+}
+
+const usage = `kubectl canary controls Canary resources.
+
+Usage:
+  kubectl canary <command> <name> [flags]
+  kubectl canary set-step <name> <step> [flags]
+
+Commands:
+  status    Show the step, replicas and pod health of a Canary
+  watch     Watch the step, replicas and pod health of a Canary
+  start     Start or resume a Canary (apply)
+  pause     Pause a running Canary (stop)
+  promote   Advance a Canary to the next step immediately
+  skip      Advance a Canary to the next step immediately (alias of promote)
+  back      Move a Canary back to the previous step
+  set-step  Move a Canary to the given step
+  abort     Abort a Canary and return all replicas to the old deployment (rollback)
+  rollback  Rollback a Canary to step 0, completes a stepped rollback in progress (rollback)
+  retry     Resume a rolled back Canary from the failed step (retry)
+  history   List the CanaryCommands issued to a Canary
+
+Flags:
+  -n, --namespace   Namespace of the Canary
+      --kubeconfig  Path to the kubeconfig file
+      --context     Name of the kubeconfig context to use
+      --interval    Refresh interval of watch (default 2s)
+      --timeout     Time to wait for a command to be processed (default 30s)
+`
+
+// options 모든 subcommand가 공유하는 flag입니다.
+type options struct {
+	namespace  string
+	kubeconfig string
+	context    string
+	interval   time.Duration
+	timeout    time.Duration
+}
+
+// invocation 파싱된 subcommand와 인자입니다.
+type invocation struct {
+	subcommand string
+	name       string
+	// command 발행할 Canary Command, status, watch, history는 비어 있습니다.
+	command string
+	// step set-step의 목표 단계
+	step *int32
+	opts options
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(0)
+	}
+
+	inv, err := parseArgs(os.Args[1], os.Args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(0)
+	}
+	if err == nil {
+		err = run(inv)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs subcommand의 flag와 위치 인자를 파싱하고 발행할 Command를 결정합니다.
+// -h, --help가 있으면 flag.ErrHelp를 반환합니다.
+func parseArgs(subcommand string, args []string) (invocation, error) {
+	inv := invocation{subcommand: subcommand}
+	fs := flag.NewFlagSet("kubectl-canary "+subcommand, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&inv.opts.namespace, "namespace", "", "Namespace of the Canary")
+	fs.StringVar(&inv.opts.namespace, "n", "", "Namespace of the Canary")
+	fs.StringVar(&inv.opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	fs.StringVar(&inv.opts.context, "context", "", "Name of the kubeconfig context to use")
+	fs.DurationVar(&inv.opts.interval, "interval", 2*time.Second, "Refresh interval of watch")
+	fs.DurationVar(&inv.opts.timeout, "timeout", 30*time.Second, "Time to wait for a command to be processed")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return invocation{}, err
+	}
+
+	switch subcommand {
+	case "status", "watch", "history":
+	case "set-step":
+		if len(positional) != 2 {
+			return invocation{}, fmt.Errorf("set-step requires a Canary name and a step")
+		}
+		step, err := strconv.ParseInt(positional[1], 10, 32)
+		if err != nil || step < 0 {
+			return invocation{}, fmt.Errorf("invalid step %q", positional[1])
+		}
+		target := int32(step)
+		inv.command, inv.step = canaryv1alpha1.CommandSetStep, &target
+		positional = positional[:1]
+	default:
+		cmd, ok := commandAliases[subcommand]
+		if !ok {
+			return invocation{}, fmt.Errorf("unknown command %q, run 'kubectl canary help' for usage", subcommand)
+		}
+		inv.command = cmd
+	}
+	if len(positional) != 1 {
+		return invocation{}, fmt.Errorf("%s requires exactly one Canary name", subcommand)
+	}
+	inv.name = positional[0]
+
+	return inv, nil
+}
+
+func run(inv invocation) error {
+	c, namespace, err := newClient(inv.opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	switch inv.subcommand {
+	case "status":
+		return status(ctx, os.Stdout, c, namespace, inv.name)
+	case "watch":
+		return watch(ctx, os.Stdout, c, namespace, inv.name, inv.opts.interval)
+	case "history":
+		return history(ctx, os.Stdout, c, namespace, inv.name)
+	}
+
+	return issueCommand(ctx, os.Stdout, c, namespace, inv.name, inv.command, inv.step, inv.opts.timeout)
+}
+
+// parseInterspersed flag와 위치 인자가 섞여 있어도 모두 파싱합니다.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newClient kubeconfig로 client를 생성하고 사용할 namespace를 반환합니다.
+func newClient(opts options) (client.Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.context}
+	overrides.Context.Namespace = opts.namespace
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c, namespace, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("kubectl-canary", func() {
+	DescribeTable("maps subcommands to commands",
+		func(subcommand string, args []string, command string) {
+			inv, err := parseArgs(subcommand, args)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inv.name).To(Equal("canary-sample"))
+			Expect(inv.command).To(Equal(command))
+		},
+		Entry("status", "status", []string{"canary-sample"}, ""),
+		Entry("watch", "watch", []string{"canary-sample"}, ""),
+		Entry("history", "history", []string{"canary-sample"}, ""),
+		Entry("start", "start", []string{"canary-sample"}, canaryv1alpha1.CommandApply),
+		Entry("pause", "pause", []string{"canary-sample"}, canaryv1alpha1.CommandStop),
+		Entry("promote", "promote", []string{"canary-sample"}, canaryv1alpha1.CommandPromote),
+		Entry("skip", "skip", []string{"canary-sample"}, canaryv1alpha1.CommandSkip),
+		Entry("back", "back", []string{"canary-sample"}, canaryv1alpha1.CommandBack),
+		Entry("abort", "abort", []string{"canary-sample"}, canaryv1alpha1.CommandRollback),
+		Entry("rollback", "rollback", []string{"canary-sample"}, canaryv1alpha1.CommandRollback),
+		Entry("retry", "retry", []string{"canary-sample"}, canaryv1alpha1.CommandRetry),
+		Entry("set-step", "set-step", []string{"canary-sample", "3"}, canaryv1alpha1.CommandSetStep),
+	)
+
+	It("should parse the target step of set-step", func() {
+		inv, err := parseArgs("set-step", []string{"canary-sample", "3"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.step).To(HaveValue(Equal(int32(3))))
+	})
+
+	It("should parse flags placed before and after the arguments", func() {
+		inv, err := parseArgs("watch", []string{"-n", "prod", "canary-sample", "--interval", "5s", "--context=staging"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inv.name).To(Equal("canary-sample"))
+		Expect(inv.opts.namespace).To(Equal("prod"))
+		Expect(inv.opts.context).To(Equal("staging"))
+		Expect(inv.opts.interval).To(Equal(5 * time.Second))
+		Expect(inv.opts.timeout).To(Equal(30 * time.Second))
+	})
+
+	DescribeTable("rejects invalid arguments",
+		func(subcommand string, args []string, message string) {
+			_, err := parseArgs(subcommand, args)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("missing name", "promote", []string{}, "promote requires exactly one Canary name"),
+		Entry("extra name", "status", []string{"a", "b"}, "status requires exactly one Canary name"),
+		Entry("missing step", "set-step", []string{"canary-sample"}, "set-step requires a Canary name and a step"),
+		Entry("negative step", "set-step", []string{"canary-sample", "--", "-1"}, `invalid step "-1"`),
+		Entry("non-numeric step", "set-step", []string{"canary-sample", "two"}, `invalid step "two"`),
+		Entry("unknown command", "deploy", []string{"canary-sample"}, `unknown command "deploy"`),
+		Entry("unknown flag", "status", []string{"canary-sample", "--unknown"}, "flag provided but not defined"),
+	)
+
+	DescribeTable("returns flag.ErrHelp for help after a subcommand",
+		func(args []string) {
+			_, err := parseArgs("promote", args)
+			Expect(err).To(MatchError(flag.ErrHelp))
+		},
+		Entry("-h", []string{"-h"}),
+		Entry("--help", []string{"--help"}),
+		Entry("-h after the name", []string{"canary-sample", "-h"}),
+	)
+})
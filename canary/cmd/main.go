@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
@@ -35,7 +36,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
 	"github.com/k8shuginn/canary-operator/internal/controller"
+	"github.com/k8shuginn/canary-operator/internal/notification"
+	"github.com/k8shuginn/canary-operator/internal/tracing"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -57,6 +61,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var tracingOpts tracing.Options
+	var cloudEventSink string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -66,6 +72,14 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&tracingOpts.Endpoint, "otlp-endpoint", "",
+		"The OTLP gRPC collector address to export traces to. Tracing is disabled if empty.")
+	flag.BoolVar(&tracingOpts.Insecure, "otlp-insecure", false,
+		"If set, traces are exported to the OTLP collector without TLS")
+	flag.StringVar(&cloudEventSink, "cloudevents-sink", "",
+		"The HTTP address to send canary lifecycle CloudEvents to. CloudEvents are disabled if empty.")
+	flag.Float64Var(&tracingOpts.SampleRatio, "trace-sample-ratio", 1.0,
+		"The ratio of canary runs to sample, between 0 and 1")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -74,6 +88,17 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "problem shutting down tracing")
+		}
+	}()
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -122,14 +147,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.CanaryReconciler{
+	notifier := notification.NewNotifier(mgr.GetAPIReader(), notification.Options{
+		Retries: 3,
+	})
+	if err = mgr.Add(notifier); err != nil {
+		setupLog.Error(err, "unable to set up notifier")
+		os.Exit(1)
+	}
+
+	emitter, err := cloudevent.NewEmitter(cloudevent.Options{
+		Sink:    cloudEventSink,
+		Retries: 3,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create cloudevents emitter")
+		os.Exit(1)
+	}
+	if emitter != nil {
+		if err = mgr.Add(emitter); err != nil {
+			setupLog.Error(err, "unable to set up cloudevents emitter")
+			os.Exit(1)
+		}
+	}
+
+	canaryReconciler := &controller.CanaryReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Cr:        controller.NewCron(mgr.GetClient(), mgr.GetAPIReader(), emitter),
+		Notifier:  notifier,
+		Emitter:   emitter,
+		Recorder:  mgr.GetEventRecorderFor("canary-controller"),
+		APIReader: mgr.GetAPIReader(),
+	}
+	if err = canaryReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Canary")
+		os.Exit(1)
+	}
+	if err = (&controller.CanaryCommandReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-		Cr:     controller.NewCron(mgr.GetClient()),
+		Canary: canaryReconciler,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Canary")
+		setupLog.Error(err, "unable to create controller", "controller", "CanaryCommand")
 		os.Exit(1)
 	}
+	if err = (&controller.ReleaseFreezeReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("releasefreeze-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ReleaseFreeze")
+		os.Exit(1)
+	}
+	// The CanaryCommand webhook records who issued a command, so it is enabled unless ENABLE_WEBHOOKS=false,
+	// e.g. when running the manager locally without webhook certificates.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&canaryv1alpha1.CanaryCommand{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "CanaryCommand")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,148 @@
+package cloudevent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/google/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Type CloudEvent type 입니다.
+type Type string
+
+const (
+	TypeStarted        Type = "io.k8shuginn.canary.started"
+	TypeStep           Type = "io.k8shuginn.canary.step"
+	TypePaused         Type = "io.k8shuginn.canary.paused"
+	TypeAnalysisResult Type = "io.k8shuginn.canary.analysis-result"
+	TypeCompleted      Type = "io.k8shuginn.canary.completed"
+	TypeRolledBack     Type = "io.k8shuginn.canary.rolled-back"
+)
+
+// Data CloudEvent data로 전송되는 Canary 정보입니다.
+type Data struct {
+	Namespace      string   `json:"namespace"`
+	Name           string   `json:"name"`
+	Step           int32    `json:"step"`
+	OldReplicas    int32    `json:"oldReplicas"`
+	NewReplicas    int32    `json:"newReplicas"`
+	OldImages      []string `json:"oldImages,omitempty"`
+	NewImages      []string `json:"newImages,omitempty"`
+	State          string   `json:"state,omitempty"`
+	Message        string   `json:"message,omitempty"`
+	AnalysisResult string   `json:"analysisResult,omitempty"`
+}
+
+// Options Emitter 설정입니다.
+type Options struct {
+	// Sink CloudEvent를 전송할 HTTP 주소
+	Sink string
+	// QueueSize 전송 대기열 크기, 대기열이 가득 차면 이벤트는 버려집니다.
+	QueueSize int
+	// Retries 전송 실패 시 재시도 횟수
+	Retries int
+	// Backoff 첫 재시도 대기 시간, 재시도마다 증가합니다.
+	Backoff time.Duration
+}
+
+type event struct {
+	eventType Type
+	data      Data
+}
+
+// Emitter Canary lifecycle CloudEvent를 structured mode HTTP로 비동기 전송합니다.
+type Emitter struct {
+	client cloudevents.Client
+	queue  chan event
+	opts   Options
+}
+
+// NewEmitter Emitter를 생성합니다. Sink가 비어있으면 nil을 반환하며, nil Emitter는 이벤트를 전송하지 않습니다.
+func NewEmitter(opts Options) (*Emitter, error) {
+	if opts.Sink == "" {
+		return nil, nil
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+
+	c, err := cloudevents.NewClientHTTP(cloudevents.WithTarget(opts.Sink))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloudevents client: %w", err)
+	}
+
+	return &Emitter{
+		client: c,
+		queue:  make(chan event, opts.QueueSize),
+		opts:   opts,
+	}, nil
+}
+
+// Emit CloudEvent를 전송 대기열에 추가합니다.
+func (e *Emitter) Emit(ctx context.Context, eventType Type, data Data) {
+	if e == nil {
+		return
+	}
+
+	select {
+	case e.queue <- event{eventType: eventType, data: data}:
+	default:
+		log.FromContext(ctx).Info("[CloudEvent] Queue is full, dropping event",
+			"namespace", data.Namespace, "name", data.Name, "type", eventType)
+	}
+}
+
+// Start ctx가 종료될 때까지 대기열의 이벤트를 순서대로 전송합니다.
+func (e *Emitter) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-e.queue:
+			if err := e.send(ctx, ev); err != nil {
+				logger.Error(err, "[CloudEvent] Failed to send event",
+					"namespace", ev.data.Namespace, "name", ev.data.Name, "type", ev.eventType)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection leader만 이벤트를 전송합니다.
+func (e *Emitter) NeedLeaderElection() bool {
+	return true
+}
+
+func (e *Emitter) send(ctx context.Context, ev event) error {
+	ce := cloudevents.NewEvent()
+	ce.SetID(uuid.NewString())
+	ce.SetType(string(ev.eventType))
+	ce.SetSource(Source(ev.data.Namespace, ev.data.Name))
+	ce.SetSubject(ev.data.Name)
+	ce.SetTime(time.Now())
+	if err := ce.SetData(cloudevents.ApplicationJSON, ev.data); err != nil {
+		return err
+	}
+
+	sendCtx := cloudevents.WithEncodingStructured(ctx)
+	if e.opts.Retries > 0 {
+		sendCtx = cloudevents.ContextWithRetriesExponentialBackoff(sendCtx, e.opts.Backoff, e.opts.Retries)
+	}
+	if result := e.client.Send(sendCtx, ce); !protocol.IsACK(result) {
+		return result
+	}
+
+	return nil
+}
+
+// Source Canary 리소스를 가리키는 CloudEvent source를 반환합니다.
+func Source(namespace, name string) string {
+	return fmt.Sprintf("/apis/canary.k8shuginn.io/v1alpha1/namespaces/%s/canaries/%s", namespace, name)
+}
@@ -0,0 +1,98 @@
+package cloudevent
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type received struct {
+	contentType string
+	body        map[string]interface{}
+}
+
+var _ = Describe("Emitter", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		sink   *httptest.Server
+		mu     sync.Mutex
+		events []received
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		events = nil
+		sink = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			raw, _ := io.ReadAll(req.Body)
+			body := map[string]interface{}{}
+			_ = json.Unmarshal(raw, &body)
+
+			mu.Lock()
+			events = append(events, received{contentType: req.Header.Get("Content-Type"), body: body})
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	})
+
+	AfterEach(func() {
+		cancel()
+		sink.Close()
+	})
+
+	receivedEvents := func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]received{}, events...)
+	}
+
+	It("should not create an emitter without a sink", func() {
+		emitter, err := NewEmitter(Options{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(emitter).To(BeNil())
+
+		// nil emitter는 이벤트를 무시합니다.
+		emitter.Emit(ctx, TypeStarted, Data{})
+	})
+
+	It("should send structured CloudEvents to the sink", func() {
+		emitter, err := NewEmitter(Options{Sink: sink.URL})
+		Expect(err).NotTo(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			Expect(emitter.Start(ctx)).To(Succeed())
+		}()
+
+		emitter.Emit(ctx, TypeStep, Data{
+			Namespace:   "default",
+			Name:        "canary-sample",
+			Step:        2,
+			OldReplicas: 6,
+			NewReplicas: 4,
+			OldImages:   []string{"nginx:1.25"},
+			NewImages:   []string{"nginx:1.26"},
+		})
+
+		Eventually(receivedEvents).Should(HaveLen(1))
+		ev := receivedEvents()[0]
+		Expect(ev.contentType).To(HavePrefix("application/cloudevents+json"))
+		Expect(ev.body).To(HaveKeyWithValue("specversion", "1.0"))
+		Expect(ev.body).To(HaveKeyWithValue("type", string(TypeStep)))
+		Expect(ev.body).To(HaveKeyWithValue("source", "/apis/canary.k8shuginn.io/v1alpha1/namespaces/default/canaries/canary-sample"))
+		Expect(ev.body).To(HaveKeyWithValue("subject", "canary-sample"))
+
+		data, ok := ev.body["data"].(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(data).To(HaveKeyWithValue("step", BeNumerically("==", 2)))
+		Expect(data).To(HaveKeyWithValue("oldReplicas", BeNumerically("==", 6)))
+		Expect(data).To(HaveKeyWithValue("newReplicas", BeNumerically("==", 4)))
+		Expect(data).To(HaveKeyWithValue("newImages", ConsistOf("nginx:1.26")))
+	})
+})
@@ -0,0 +1,14 @@
+package cloudevent
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCloudEvent(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "CloudEvent Suite")
+}
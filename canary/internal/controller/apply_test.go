@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Deployment server-side apply", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	var reconciler *CanaryReconciler
+
+	// newDeployment GitOps 도구가 생성한 것처럼 image와 annotation이 있는 Deployment를 생성합니다.
+	newDeployment := func(name string, replicas int32) *appsv1.Deployment {
+		labels := map[string]string{"app": name}
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        name,
+				Annotations: map[string]string{"argocd.argoproj.io/tracking-id": name},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: name + ":v1"}}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, deploy)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, deploy)).To(Succeed())
+		})
+		return deploy
+	}
+
+	newCanary := func(name string) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.OldDeployment, spec.NewDeployment = name+"-old", name+"-new"
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, nil)
+		canary.Status.State = canaryv1alpha1.PhaseProgressing
+		canary.Status.CurrentStep = 1
+		return canary
+	}
+
+	// changeImage 다른 field manager가 Deployment image를 변경합니다.
+	changeImage := func(deploy *appsv1.Deployment, image string) {
+		latest := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deploy), latest)).To(Succeed())
+		latest.Spec.Template.Spec.Containers[0].Image = image
+		Expect(k8sClient.Update(ctx, latest, client.FieldOwner("argocd-controller"))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler, _ = newTestReconciler()
+	})
+
+	It("should apply only replicas and the owner reference", func() {
+		canary := newCanary("apply-fields")
+		oldDeploy, newDeploy := newDeployment("apply-fields-old", 10), newDeployment("apply-fields-new", 0)
+
+		// Canary가 읽은 뒤에 변경된 필드는 유지되어야 합니다.
+		changeImage(oldDeploy, "apply-fields-old:v2")
+		Expect(reconciler.syncDeployments(ctx, logger, canary, oldDeploy.DeepCopy(), newDeploy.DeepCopy())).To(BeTrue())
+
+		for name, replicas := range map[string]int32{"apply-fields-old": 8, "apply-fields-new": 2} {
+			deploy := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, deploy)).To(Succeed())
+			Expect(*deploy.Spec.Replicas).To(Equal(replicas))
+			Expect(deploy.Annotations).To(HaveKeyWithValue("argocd.argoproj.io/tracking-id", name))
+			Expect(deploy.OwnerReferences).To(ConsistOf(And(
+				HaveField("UID", canary.UID),
+				HaveField("Kind", "Canary"),
+				HaveField("Controller", HaveValue(BeTrue())),
+			)))
+		}
+
+		deploy := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(oldDeploy), deploy)).To(Succeed())
+		Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal("apply-fields-old:v2"))
+	})
+
+	It("should keep owner references added by other controllers", func() {
+		canary := newCanary("apply-owners")
+		oldDeploy, newDeploy := newDeployment("apply-owners-old", 10), newDeployment("apply-owners-new", 0)
+
+		other := metav1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Application", Name: "app", UID: "application-uid"}
+		oldDeploy.OwnerReferences = []metav1.OwnerReference{other}
+		Expect(k8sClient.Update(ctx, oldDeploy)).To(Succeed())
+
+		Expect(reconciler.syncDeployments(ctx, logger, canary, oldDeploy, newDeploy)).To(BeTrue())
+		Expect(oldDeploy.OwnerReferences).To(ConsistOf(other, HaveField("UID", canary.UID)))
+		Expect(*oldDeploy.Spec.Replicas).To(Equal(int32(8)))
+
+		canary.Finalizers = []string{CanaryFinalizer}
+		Expect(reconciler.toBeDeleted(ctx, logger, canary, oldDeploy, newDeploy)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(oldDeploy), oldDeploy)).To(Succeed())
+		Expect(oldDeploy.OwnerReferences).To(ConsistOf(other))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(newDeploy), newDeploy)).To(Succeed())
+		Expect(newDeploy.OwnerReferences).To(BeEmpty())
+	})
+
+	It("should not write when replicas and owner are in sync", func() {
+		canary := newCanary("apply-synced")
+		oldDeploy, newDeploy := newDeployment("apply-synced-old", 10), newDeployment("apply-synced-new", 0)
+		Expect(reconciler.syncDeployments(ctx, logger, canary, oldDeploy, newDeploy)).To(BeTrue())
+
+		resourceVersion := oldDeploy.ResourceVersion
+		Expect(reconciler.syncDeployments(ctx, logger, canary, oldDeploy, newDeploy)).To(BeFalse())
+		Expect(oldDeploy.ResourceVersion).To(Equal(resourceVersion))
+	})
+})
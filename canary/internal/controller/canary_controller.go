@@ -18,41 +18,44 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	appsv1apply "k8s.io/client-go/applyconfigurations/apps/v1"
+	metav1apply "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strconv"
 	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
+	"github.com/k8shuginn/canary-operator/internal/notification"
+	"github.com/k8shuginn/canary-operator/internal/tracing"
 )
 
 const (
-	Command           = "canary.k8shuginn.io/command"
-	CommandApply      = "apply"
-	CommandStop       = "stop"
-	CommandRollback   = "rollback"
-	CommandCompletion = "completion"
-)
-
-const (
-	StateRunning  = "running"  // 실행 중
-	StateError    = "error"    // 에러 발생 시
-	StateStop     = "stop"     // 대기중, 롤백
-	StateComplete = "complete" // 완료
-)
-
-const (
+	Command              = "canary.k8shuginn.io/command"
 	AnnotationLastUpdate = "canary.k8shuginn.io/last-update"
+	AnnotationStep       = "canary.k8shuginn.io/step"
 	CanaryFinalizer      = "canary.k8shuginn.io/finalizer"
 )
 
@@ -61,7 +64,17 @@ type CanaryReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	Cr *Cron
+	Cr       *Cron
+	Notifier *notification.Notifier
+	Emitter  *cloudevent.Emitter
+	Recorder record.EventRecorder
+
+	// APIReader cache를 거치지 않고 dynamicTotal, holidays ConfigMap을 읽습니다. ConfigMap informer가 cluster 전체를 watch하지 않도록
+	// manager의 API reader를 사용하며, 비어 있으면 Client로 읽습니다.
+	APIReader client.Reader
+
+	// Clock 현재 시간을 제공합니다. 비어 있으면 실제 시간을 사용합니다.
+	Clock clock.PassiveClock
 }
 
 //+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=canaries,verbs=get;list;watch;create;update;patch;delete
@@ -70,6 +83,7 @@ type CanaryReconciler struct {
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -97,15 +111,25 @@ func (r *CanaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	// 이전 버전의 문자열 state는 phase로 변환하여 저장합니다.
+	if _, ok := legacyPhases[string(canary.Status.State)]; ok {
+		logger.Info("[Reconcile] Converting Canary state to phase", "namespace", req.Namespace, "name", req.Name, "state", canary.Status.State)
+		if err = patchStatus(ctx, r.Client, canary, func() bool { return normalizePhase(canary) }); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary phase", "namespace", req.Namespace, "name", req.Name)
+		}
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	// Canary run의 trace에 Reconcile span을 연결합니다.
+	ctx, span := tracing.Start(ctx, canary.Status.TraceID, canary.Status.SpanID, "Reconcile", tracing.CanaryAttributes(req.Namespace, req.Name)...)
+	defer span.End()
+
 	// Canary에 finalizer가 없으면 추가합니다.
 	if !controllerutil.ContainsFinalizer(canary, CanaryFinalizer) {
 		logger.Info("[Reconcile] Adding finalizer to the Canary")
-		if ok := controllerutil.AddFinalizer(canary, CanaryFinalizer); !ok {
-			logger.Error(err, "[Reconcile] Failed to add finalizer to the Canary", "namespace", req.Namespace, "name", req.Name)
-			return ctrl.Result{Requeue: true}, err
-		}
-
-		if err = r.Update(ctx, canary); err != nil {
+		if err = patchMeta(ctx, r.Client, canary, func() bool {
+			return controllerutil.AddFinalizer(canary, CanaryFinalizer)
+		}); err != nil {
 			logger.Error(err, "[Reconcile] Failed to update Canary with finalizer", "namespace", req.Namespace, "name", req.Name)
 		}
 		return ctrl.Result{}, err
@@ -121,81 +145,195 @@ func (r *CanaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	// oldDeployment, newDeployment owner reference 제거
 	isToBeDeleted := canary.GetDeletionTimestamp() != nil
 	if isToBeDeleted {
-		r.toBeDeleted(ctx, logger, canary, oldDeploy, newDeploy)
+		if err := r.toBeDeleted(ctx, logger, canary, oldDeploy, newDeploy); err != nil {
+			return ctrl.Result{}, err
+		}
+		deleteMetrics(req.Namespace, req.Name)
 		return ctrl.Result{}, nil
 	}
 
 	// oldDeployment, newDeployment이 없으면 에러 처리
 	if msg, ok := isNotExists(oldDeploy, newDeploy); ok {
-		canary.Status.OldReplicas = 0
-		canary.Status.NewReplicas = 0
-		canary.Status.State = StateError
-		canary.Status.Message = msg
-		_ = r.Status().Update(ctx, canary)
 		r.Cr.Delete(req.Namespace, req.Name)
 		logger.Info("[Reconcile] Deployment is not found.", "namespace", req.Namespace, "name", req.Name)
-		return ctrl.Result{}, nil
+		err = patchStatus(ctx, r.Client, canary, func() bool {
+			canary.Status.OldReplicas = 0
+			canary.Status.NewReplicas = 0
+			canary.Status.State = canaryv1alpha1.PhaseFailed
+			canary.Status.Message = msg
+			return true
+		})
+		recordStatus(canary)
+		return ctrl.Result{}, err
+	}
+
+	// Deployment가 다시 생성되면 Failed phase를 다시 계산합니다.
+	if canary.Status.State == canaryv1alpha1.PhaseFailed {
+		if err = patchStatus(ctx, r.Client, canary, func() bool { return recoverPhase(canary) }); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary phase", "namespace", req.Namespace, "name", req.Name)
+			return ctrl.Result{}, err
+		}
+		logger.Info("[Reconcile] Canary is recovered", "namespace", req.Namespace, "name", req.Name, "state", canary.Status.State)
+		recordStatus(canary)
+		return ctrl.Result{Requeue: true}, nil
 	}
 
 	// Annotation에 Command가 있으면 Command 처리
-	if ok := r.applyCommand(ctx, logger, canary); ok {
+	if ok, err := r.applyCommand(ctx, logger, canary, oldDeploy, newDeploy); ok {
+		return ctrl.Result{}, err
+	}
+
+	// autoStart, startAt이 설정되어 있으면 Command 없이 시작합니다.
+	startAfter := r.autoStart(ctx, logger, canary, oldDeploy, newDeploy)
+
+	// new Deployment template 변경 처리
+	r.checkNewTemplate(ctx, logger, canary, oldDeploy, newDeploy)
+
+	// Canary 외부에서 변경된 Deployment 처리
+	if skipSync := r.detectDrift(ctx, logger, canary, oldDeploy, newDeploy); skipSync {
+		logger.Info("[Reconcile] Canary is paused by drift", "namespace", req.Namespace, "name", req.Name)
 		return ctrl.Result{}, nil
 	}
 
 	// Deployment replicas 동기화
+	r.syncTotalReplicas(ctx, logger, canary, oldDeploy, newDeploy)
 	if isUpdate := r.syncDeployments(ctx, logger, canary, oldDeploy, newDeploy); isUpdate {
 		logger.Info("[Reconcile] Deployment replicas are updated", "namespace", req.Namespace, "name", req.Name)
 	}
+	r.syncPodDisruptionBudget(ctx, logger, canary, oldDeploy, newDeploy)
+
+	// progressDeadline, maxDuration을 초과하면 degraded 상태로 변경하거나 rollback
+	exceeded, deadlineAfter := r.checkDeadline(ctx, logger, canary, oldDeploy, newDeploy)
+	if exceeded {
+		return ctrl.Result{Requeue: true}, nil
+	}
 
 	// new deployment이 crash되었을 경우 rollback
-	if canary.Spec.EnableRollback {
+	// 진행 중인 Canary만 확인하여, 롤백 후 종료 중인 crash pod로 다시 롤백하지 않습니다.
+	if canary.Spec.EnableRollback && isActive(canary) {
 		if isRollback := r.isCrash(ctx, logger, canary, oldDeploy, newDeploy); isRollback {
 			return ctrl.Result{Requeue: true}, nil
 		}
 	}
 
 	// Status Update
-	if isCronDelete := r.stateUpdate(ctx, logger, canary, oldDeploy, newDeploy); isCronDelete {
+	isCronDelete, err := r.stateUpdate(ctx, logger, canary, oldDeploy, newDeploy)
+	if isCronDelete {
 		r.Cr.Delete(req.Namespace, req.Name)
 		logger.Info("[Reconcile] Cron is deleted", "namespace", req.Namespace, "name", req.Name)
 	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// stepInterval이면 현재 단계가 healthy 상태가 된 후 interval이 지나면 다음 단계로 진행합니다.
+	requeueAfter := r.stepByInterval(ctx, logger, canary, oldDeploy, newDeploy)
+	for _, after := range []time.Duration{startAfter, deadlineAfter} {
+		if after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+			requeueAfter = after
+		}
+	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
-// stateUpdate Canary 상태를 업데이트합니다.
+// stateUpdate Canary phase를 업데이트합니다.
+// phase는 phaseTransitions에 정의된 이동만 허용하며, 허용되지 않는 이동은 phase를 변경하지 않습니다.
+// Cron이 동시에 단계를 변경하면 최신 Canary로 다시 계산하므로 변경된 단계를 덮어쓰지 않습니다.
 func (r *CanaryReconciler) stateUpdate(
 	ctx context.Context,
 	logger logr.Logger,
 	canary *canaryv1alpha1.Canary,
 	oldDeploy, newDeploy *appsv1.Deployment,
-) bool {
-	cronDelete := false
-
-	_ = r.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Name}, canary)
-	canary.Status.OldReplicas = *oldDeploy.Spec.Replicas
-	canary.Status.NewReplicas = *newDeploy.Spec.Replicas
-	if canary.Status.NewReplicas == canary.Spec.TotalReplicas || canary.Status.State == StateComplete {
-		canary.Status.Message = "Canary is complete"
-		canary.Status.State = StateComplete
-		cronDelete = true
-	} else if canary.Status.State == StateStop {
-		cronDelete = true
-	} else if canary.Status.State == StateError {
-	} else if canary.Status.State == StateRunning {
-		canary.Status.Message = "Canary is running"
-		_ = r.Cr.Apply(canary.Namespace, canary.Name, canary.Spec.CronSchedule, canary.Spec.OldDeployment, canary.Spec.NewDeployment)
-	} else {
-		canary.Status.State = StateStop
-		canary.Status.Message = "Canary is Pending"
-		cronDelete = true
-	}
-
-	if err := r.Status().Update(ctx, canary); err != nil {
+) (bool, error) {
+	var cron cronAction
+	var event canaryv1alpha1.NotificationEvent
+
+	transition := func(to canaryv1alpha1.CanaryPhase) bool {
+		if err := setPhase(canary, to); err != nil {
+			logger.Error(err, "[Reconcile] Canary phase is not changed", "namespace", canary.Namespace, "name", canary.Name)
+			return false
+		}
+		return true
+	}
+	err := patchStatus(ctx, r.Client, canary, func() bool {
+		cron, event = cronDelete, ""
+		recordObserved(canary, oldDeploy, newDeploy)
+
+		current := phase(canary)
+		switch {
+		case current == canaryv1alpha1.PhaseRollingBack:
+			// 단계별 롤백이 0 단계에 도달하면 롤백을 완료합니다.
+			if canary.Status.CurrentStep == 0 {
+				if transition(canaryv1alpha1.PhaseRolledBack) {
+					event = canaryv1alpha1.NotificationRolledBack
+					canary.Status.Message = fmt.Sprintf("[%s] Canary is rollbacked.", r.now().Format(time.RFC3339))
+				}
+			} else {
+				cron = cronApplyRollback
+			}
+		case isRolledOut(canary, newDeploy) || current == canaryv1alpha1.PhaseCompleted ||
+			(canary.Spec.EnableHPA && canary.Status.CurrentStep >= maxStep(canary)):
+			if current != canaryv1alpha1.PhaseCompleted && transition(canaryv1alpha1.PhaseCompleted) {
+				event = canaryv1alpha1.NotificationCompleted
+			}
+			canary.Status.Message = "Canary is complete"
+		case current == canaryv1alpha1.PhaseFailed:
+		case isActive(canary):
+			// scaling 정책이나 PodDisruptionBudget으로 현재 단계의 replicas에 도달하지 못하거나
+			// pod가 available 상태가 아니면 Promoting, 모두 available 상태이면 Progressing입니다.
+			transition(stepPhase(isStepHealthy(canary, oldDeploy, newDeploy)))
+			canary.Status.Message = "Canary is running"
+			if canary.Status.State == canaryv1alpha1.PhasePromoting {
+				canary.Status.Message = fmt.Sprintf("Canary is running, waiting for available replicas to scale to step %d", canary.Status.CurrentStep)
+			}
+			// stepInterval은 reconcile에서 진행하므로 Cron을 사용하지 않습니다.
+			// cronSchedule을 파싱할 수 없으면 단계를 진행할 수 없으므로 message에 이유를 기록합니다.
+			if canary.Spec.StepInterval == nil {
+				if err := validateCronSchedule(canary.Spec.CronSchedule); err != nil {
+					canary.Status.Message = fmt.Sprintf("Canary is running, but cronSchedule %q is invalid: %v", canary.Spec.CronSchedule, err)
+				} else {
+					cron = cronApply
+					canary.Status.NextStepTime = nextCronTime(canary.Spec.CronSchedule, r.now())
+				}
+			}
+		case canary.Status.State == "":
+			// 새로 생성된 Canary는 Pending phase로 Command를 기다립니다.
+			transition(canaryv1alpha1.PhasePending)
+			canary.Status.Message = "Canary is Pending"
+			if canary.Spec.StartAt != nil && canary.Status.StartTime == nil {
+				canary.Status.Message = fmt.Sprintf("Canary is Pending, scheduled to start at %s", canary.Spec.StartAt.Format(time.RFC3339))
+			}
+		default:
+			// Pending, Paused, RolledBack은 Command를 기다립니다.
+		}
+		if !isActive(canary) {
+			canary.Status.NextStepTime = nil
+		}
+		return true
+	})
+	if err != nil {
 		logger.Error(err, "[Reconcile] Failed to update Canary status")
+		return false, err
+	}
+	recordStatus(canary)
+	if event != "" {
+		r.publish(ctx, canary, event, oldDeploy, newDeploy)
 	}
 
-	return cronDelete
+	// Cron은 patch가 성공한 뒤에 한 번만 변경합니다.
+	switch cron {
+	case cronApply:
+		if err := r.Cr.Apply(canary.Namespace, canary.Name, canary.Spec.CronSchedule, canary.Spec.OldDeployment, canary.Spec.NewDeployment); err != nil {
+			logger.Error(err, "[Reconcile] Failed to apply Cron", "namespace", canary.Namespace, "name", canary.Name)
+		}
+	case cronApplyRollback:
+		if err := r.Cr.ApplyRollback(canary.Namespace, canary.Name, rollbackInterval(canary), canary.Spec.OldDeployment, canary.Spec.NewDeployment); err != nil {
+			logger.Error(err, "[Reconcile] Failed to apply rollback Cron", "namespace", canary.Namespace, "name", canary.Name)
+		}
+	}
+
+	return cron == cronDelete, nil
 }
 
 // isCrash new deployment이 crash되었을 경우 rollback합니다.
@@ -205,8 +343,15 @@ func (r *CanaryReconciler) isCrash(
 	canary *canaryv1alpha1.Canary,
 	oldDeploy, newDeploy *appsv1.Deployment,
 ) bool {
+	ctx, span := tracing.Tracer().Start(ctx, "isCrash")
+	defer span.End()
+
 	podList := corev1.PodList{}
+	_, analysisSpan := tracing.Tracer().Start(ctx, "analysis.podRestarts")
 	if err := r.List(ctx, &podList, client.InNamespace(newDeploy.Namespace), client.MatchingLabels(newDeploy.Spec.Selector.MatchLabels)); err != nil {
+		analysisSpan.RecordError(err)
+		analysisSpan.SetStatus(codes.Error, "failed to list pods")
+		analysisSpan.End()
 		logger.Error(err, "[Reconcile] Failed to list Pods", "namespace", newDeploy.Namespace, "name", newDeploy.Name)
 		return false
 	}
@@ -221,18 +366,43 @@ LOOP:
 			}
 		}
 	}
+	analysisSpan.SetAttributes(attribute.Int("pods", len(podList.Items)), attribute.Bool("crash", isRollback))
+	analysisSpan.End()
 
 	if isRollback {
+		recordAnalysis(canary.Namespace, canary.Name, AnalysisResultCrash)
+		emitAnalysis(ctx, r.Emitter, AnalysisResultCrash, canary, oldDeploy, newDeploy)
+		span.AddEvent("rollback")
+
 		// Canary 상태 변경
-		if err := r.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Name}, canary); err != nil {
-			logger.Error(err, "[Reconcile] Failed to get Canary after rollback")
+		var event canaryv1alpha1.NotificationEvent
+		var rollbackErr error
+		active := false
+		if err := patchStatus(ctx, r.Client, canary, func() bool {
+			// 그 사이 Command로 멈추거나 롤백된 Canary는 다시 롤백하지 않습니다.
+			if active = isActive(canary); !active {
+				return false
+			}
+			recordObserved(canary, oldDeploy, newDeploy)
+			event, rollbackErr = r.rollback(canary, "")
+			return rollbackErr == nil
+		}); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary status after rollback", "namespace", canary.Namespace, "name", canary.Name)
+			return true
+		}
+		if !active {
+			return true
+		}
+		if rollbackErr != nil {
+			logger.Error(rollbackErr, "[Reconcile] Failed to rollback Canary", "namespace", canary.Namespace, "name", canary.Name)
+			return false
 		}
-
-		canary.Status.CurrentStep = 0
-		canary.Status.State = StateStop
-		canary.Status.Message = fmt.Sprintf("[%s] Canary is rollbacked.", time.Now().Format(time.RFC3339))
-		_ = r.Status().Update(ctx, canary)
 		r.Cr.Delete(canary.Namespace, canary.Name)
+		recordStatus(canary)
+		recordRollback(canary.Namespace, canary.Name, RollbackReasonCrash)
+		if event != "" {
+			r.publish(ctx, canary, event, oldDeploy, newDeploy)
+		}
 		logger.Info("[Reconcile] Canary is rollbacked", "namespace", canary.Namespace, "name", canary.Name)
 		return true
 	}
@@ -247,116 +417,487 @@ func (r *CanaryReconciler) syncDeployments(
 	canary *canaryv1alpha1.Canary,
 	oldDeploy, newDeploy *appsv1.Deployment,
 ) bool {
-	// Owner만 추가되는 경우 true, Owner가 추가되지 않는 경우 false
-	isOldUpdate := r.appendOwnerIfNotExists(canary, oldDeploy)
-	if *oldDeploy.Spec.Replicas != canary.Spec.TotalReplicas-(canary.Spec.StepReplicas*canary.Status.CurrentStep) {
-		*oldDeploy.Spec.Replicas = canary.Spec.TotalReplicas - (canary.Spec.StepReplicas * canary.Status.CurrentStep)
-		isOldUpdate = true
+	ctx, span := tracing.Tracer().Start(ctx, "syncDeployments", trace.WithAttributes(attribute.Int("step", int(canary.Status.CurrentStep))))
+	defer span.End()
+
+	oldReplicas, newReplicas := desiredReplicas(canary)
+	if canary.Spec.EnableHPA {
+		// HPA가 있는 Deployment의 replicas는 HPA가 관리합니다.
+		oldReplicas, newReplicas = r.syncHPAs(ctx, logger, canary, oldDeploy, newDeploy, oldReplicas, newReplicas)
+	} else if canary.Spec.Scaling != nil {
+		oldReplicas, newReplicas = surgeReplicas(canary, oldDeploy, newDeploy, oldReplicas, newReplicas)
+	}
+	// 진행 중에는 PodDisruptionBudget을 위반하지 않도록 줄어드는 replicas를 제한합니다.
+	// 두 Deployment에 매칭되는 PodDisruptionBudget의 disruptionsAllowed를 함께 사용합니다.
+	if isActive(canary) && !canary.Spec.EnableHPA {
+		spent := map[types.UID]int32{}
+		oldReplicas = r.limitByPDB(ctx, logger, oldDeploy, oldReplicas, spent)
+		newReplicas = r.limitByPDB(ctx, logger, newDeploy, newReplicas, spent)
 	}
+
+	// Owner Reference가 없거나 replicas가 다른 경우에만 server-side apply로 동기화합니다.
+	isOldUpdate := !hasCanaryOwner(oldDeploy) || *oldDeploy.Spec.Replicas != oldReplicas
 	if isOldUpdate {
-		if err := r.Update(ctx, oldDeploy); err != nil {
+		if err := r.applyDeployment(ctx, canary, oldDeploy, oldReplicas); err != nil {
+			span.RecordError(err)
 			logger.Error(err, "[Reconcile] Failed to sync update oldDeployment", "namespace", canary.Namespace, "name", canary.Spec.OldDeployment)
 		}
 	}
 
-	isNewUpdate := r.appendOwnerIfNotExists(canary, newDeploy)
-	if *newDeploy.Spec.Replicas != canary.Spec.StepReplicas*canary.Status.CurrentStep {
-		*newDeploy.Spec.Replicas = canary.Spec.StepReplicas * canary.Status.CurrentStep
-		isNewUpdate = true
-	}
+	isNewUpdate := !hasCanaryOwner(newDeploy) || *newDeploy.Spec.Replicas != newReplicas
 	if isNewUpdate {
-		if err := r.Update(ctx, newDeploy); err != nil {
+		if err := r.applyDeployment(ctx, canary, newDeploy, newReplicas); err != nil {
+			span.RecordError(err)
 			logger.Error(err, "[Reconcile] Failed to sync update newDeployment", "namespace", canary.Namespace, "name", canary.Spec.NewDeployment)
 		}
 	}
 
+	span.SetAttributes(
+		attribute.Int("oldReplicas", int(*oldDeploy.Spec.Replicas)),
+		attribute.Int("newReplicas", int(*newDeploy.Spec.Replicas)),
+	)
 	return isOldUpdate || isNewUpdate
 }
 
-// appendOwnerIfNotExists Owner Reference가 없으면 Owner Reference를 추가합니다.
-func (r *CanaryReconciler) appendOwnerIfNotExists(canary *canaryv1alpha1.Canary, deploy *appsv1.Deployment) bool {
-	isExists := false
-	for _, owner := range deploy.OwnerReferences {
-		if owner.Kind == "Canary" || owner.UID == canary.UID {
-			isExists = true
-			break
+// applyDeployment server-side apply로 Deployment의 spec.replicas와 Canary owner reference만 적용합니다.
+// FieldManager가 소유한 필드만 변경하므로 Argo CD, Flux 같은 GitOps 도구가 관리하는 다른 필드를 덮어쓰지 않습니다.
+// 적용 결과는 deploy에 반영됩니다.
+func (r *CanaryReconciler) applyDeployment(
+	ctx context.Context,
+	canary *canaryv1alpha1.Canary,
+	deploy *appsv1.Deployment,
+	replicas int32,
+) error {
+	apply := appsv1apply.Deployment(deploy.Name, deploy.Namespace).
+		WithSpec(appsv1apply.DeploymentSpec().WithReplicas(replicas))
+	owner, err := r.canaryOwnerReference(canary, deploy)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		apply.WithOwnerReferences(owner)
+	}
+
+	data, err := json.Marshal(apply)
+	if err != nil {
+		return err
+	}
+
+	return r.Patch(ctx, deploy, client.RawPatch(types.ApplyPatchType, data), client.FieldOwner(FieldManager), client.ForceOwnership)
+}
+
+// canaryOwnerReference Deployment에 적용할 Canary controller owner reference를 반환합니다.
+// 다른 Canary나 controller가 이미 Deployment를 소유하고 있으면 nil을 반환합니다.
+func (r *CanaryReconciler) canaryOwnerReference(
+	canary *canaryv1alpha1.Canary,
+	deploy *appsv1.Deployment,
+) (*metav1apply.OwnerReferenceApplyConfiguration, error) {
+	if !metav1.IsControlledBy(deploy, canary) {
+		for _, owner := range deploy.OwnerReferences {
+			if owner.Kind == "Canary" || (owner.Controller != nil && *owner.Controller) {
+				return nil, nil
+			}
 		}
 	}
-	if !isExists {
-		_ = controllerutil.SetControllerReference(canary, deploy, r.Scheme)
-		return true
+
+	gvk, err := apiutil.GVKForObject(canary, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return metav1apply.OwnerReference().
+		WithAPIVersion(gvk.GroupVersion().String()).
+		WithKind(gvk.Kind).
+		WithName(canary.Name).
+		WithUID(canary.UID).
+		WithController(true).
+		WithBlockOwnerDeletion(true), nil
+}
+
+// hasCanaryOwner Deployment에 Canary Owner Reference가 있는지 확인합니다.
+func hasCanaryOwner(deploy *appsv1.Deployment) bool {
+	for _, owner := range deploy.OwnerReferences {
+		if owner.Kind == "Canary" {
+			return true
+		}
 	}
 
 	return false
 }
 
 // applyCommand Annotation에 Command가 있으면 Command 처리합니다.
+// Annotation은 CanaryCommand 리소스 이전의 방식으로, 하위 호환을 위해 유지합니다.
+// status를 저장하지 못하면 Annotation을 남겨두어 다음 reconcile에서 다시 처리합니다.
 func (r *CanaryReconciler) applyCommand(
 	ctx context.Context,
 	logger logr.Logger,
 	canary *canaryv1alpha1.Canary,
-) bool {
-	if cmd, ok := canary.Annotations[Command]; ok {
-		switch strings.ToLower(cmd) {
-		case CommandApply:
-			canary.Status.State = StateRunning
-		case CommandRollback:
-			canary.Status.CurrentStep = 0
-			fallthrough
-		case CommandStop:
-			canary.Status.State = StateStop
-			r.Cr.Delete(canary.Namespace, canary.Name)
-		case CommandCompletion:
-			canary.Status.State = StateComplete
-			canary.Status.CurrentStep = canary.Spec.TotalReplicas / canary.Spec.StepReplicas
-			r.Cr.Delete(canary.Namespace, canary.Name)
-		}
-
-		_ = r.Status().Update(ctx, canary)
-		_ = r.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Name}, canary)
+	oldDeploy, newDeploy *appsv1.Deployment,
+) (bool, error) {
+	cmd, ok := canary.Annotations[Command]
+	if !ok {
+		return false, nil
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "applyCommand", trace.WithAttributes(attribute.String("command", cmd)))
+	defer span.End()
+
+	// status를 변경하기 전에 annotation을 먼저 제거하여 제거에 실패해도 promote, skip, back이 다시 적용되지 않도록 합니다.
+	// 충돌로 다시 읽은 Canary의 Command가 바뀌었으면 새 Command의 이벤트에서 처리합니다.
+	stepValue, hasStep := "", false
+	removed := false
+	if err := patchMeta(ctx, r.Client, canary, func() bool {
+		removed = canary.Annotations[Command] == cmd
+		if !removed {
+			return false
+		}
+		stepValue, hasStep = canary.Annotations[AnnotationStep]
 		delete(canary.Annotations, Command)
-		if err := r.Update(ctx, canary); err != nil {
-			logger.Error(err, "[Reconcile] Failed to update Canary with command", "namespace", canary.Namespace, "name", canary.Name)
+		delete(canary.Annotations, AnnotationStep)
+		return true
+	}); err != nil {
+		span.RecordError(err)
+		logger.Error(err, "[Reconcile] Failed to update Canary with command", "namespace", canary.Namespace, "name", canary.Name)
+		return true, err
+	}
+	if !removed {
+		return true, nil
+	}
+
+	var result commandResult
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		var step *int32
+		var err error
+		if hasStep {
+			step, err = parseStep(stepValue)
 		}
 
+		result = commandResult{}
+		if err == nil {
+			result, err = r.runCommand(canary, cmd, step)
+		}
+		if err != nil {
+			// 잘못된 Command는 상태 메시지로 알려줍니다.
+			span.RecordError(err)
+			canary.Status.Message = err.Error()
+			logger.Info("[Reconcile] Command is rejected", "namespace", canary.Namespace, "name", canary.Name, "command", cmd, "reason", err.Error())
+		}
 		return true
+	}); err != nil {
+		span.RecordError(err)
+		logger.Error(err, "[Reconcile] Failed to update Canary status with command", "namespace", canary.Namespace, "name", canary.Name, "command", cmd)
+		return true, err
 	}
+	r.finishCommand(ctx, logger, canary, result, oldDeploy, newDeploy)
 
-	return false
+	return true, nil
+}
+
+// commandResult status patch가 성공한 뒤 finishCommand에서 한 번만 실행할 Command의 후속 작업입니다.
+// runCommand는 patch가 충돌하면 다시 실행되므로 Cron 변경, trace 생성, metric 기록, 이벤트 전송을 직접 실행하지 않습니다.
+type commandResult struct {
+	// event 전송할 lifecycle 이벤트
+	event canaryv1alpha1.NotificationEvent
+	// cronDelete 등록된 Cron job을 제거합니다.
+	cronDelete bool
+	// startRun 새로운 Canary run의 trace를 생성합니다.
+	startRun bool
+	// rollbackReason rollback metric에 기록할 reason
+	rollbackReason string
+}
+
+// runCommand Command를 Canary status에 적용하고 patch 후에 실행할 작업을 반환합니다.
+// 현재 phase에서 허용되지 않거나 알 수 없는 Command는 status를 변경하지 않고 에러를 반환합니다.
+// step은 setstep Command의 목표 단계입니다.
+func (r *CanaryReconciler) runCommand(
+	canary *canaryv1alpha1.Canary,
+	cmd string,
+	step *int32,
+) (commandResult, error) {
+	var result commandResult
+	lastStep := maxStep(canary)
+	prevStep, prevActive := canary.Status.CurrentStep, isActive(canary)
+
+	reject := func(to canaryv1alpha1.CanaryPhase) error {
+		if err := canTransition(canary.Status.State, to); err != nil {
+			return fmt.Errorf("command %q is rejected: %w", cmd, err)
+		}
+		return nil
+	}
+
+	switch strings.ToLower(cmd) {
+	case canaryv1alpha1.CommandApply:
+		if canary.Status.State == canaryv1alpha1.PhaseCompleted {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is already complete", cmd)
+		}
+		// 이미 진행 중이면 phase를 유지하고, 다시 시작하면 stateUpdate에서 단계의 상태에 따라 Progressing으로 변경합니다.
+		next := canaryv1alpha1.PhasePromoting
+		if prevActive {
+			next = canary.Status.State
+		}
+		if err := reject(next); err != nil {
+			return commandResult{}, err
+		}
+		// 시작 시간은 새로운 Canary run을 시작할 때만 기록하므로, 멈춘 Canary를 재개해도 maxDuration은 늘어나지 않습니다.
+		newRun := isNewRun(canary)
+		if !newRun && maxDurationExceeded(canary, r.now()) {
+			return commandResult{}, fmt.Errorf("command %q is rejected: max duration %s is exceeded", cmd, canary.Spec.MaxDuration.Duration)
+		}
+		if newRun {
+			canary.Status.TraceID, canary.Status.SpanID = "", ""
+			canary.Status.StartTime = &metav1.Time{Time: r.now()}
+		}
+		if canary.Status.StartTime == nil {
+			canary.Status.StartTime = &metav1.Time{Time: r.now()}
+		}
+		// tracing을 사용하고 진행 중인 trace가 없으면 새로운 trace를 생성합니다.
+		result.startRun = tracing.Enabled() && canary.Status.TraceID == ""
+		if !prevActive {
+			result.event = canaryv1alpha1.NotificationStarted
+		}
+		if meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionDegraded) {
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionDegraded, Status: metav1.ConditionFalse, Reason: "Resumed", Message: "Canary is applied after degraded",
+			})
+		}
+		// drift로 멈춘 Canary를 재개하면 현재 단계의 replicas로 되돌립니다.
+		if meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionDrifted) {
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionDrifted, Status: metav1.ConditionFalse, Reason: "Resumed", Message: "Canary is applied after drift",
+			})
+		}
+		canary.Status.State = next
+	case canaryv1alpha1.CommandRollback:
+		if canary.Status.State == canaryv1alpha1.PhaseRollingBack {
+			// 진행 중인 단계별 롤백을 즉시 완료합니다.
+			canary.Status.CurrentStep = 0
+			canary.Status.State = canaryv1alpha1.PhaseRolledBack
+			canary.Status.Message = fmt.Sprintf("[%s] Canary is rollbacked by command.", r.now().Format(time.RFC3339))
+			result.cronDelete = true
+			result.event = canaryv1alpha1.NotificationRolledBack
+			break
+		}
+		if !prevActive && canary.Status.CurrentStep == 0 {
+			return commandResult{}, fmt.Errorf("command %q is rejected: nothing to rollback", cmd)
+		}
+		var err error
+		if result.event, err = r.rollback(canary, " by command"); err != nil {
+			return commandResult{}, fmt.Errorf("command %q is rejected: %w", cmd, err)
+		}
+		result.cronDelete = true
+		result.rollbackReason = RollbackReasonCommand
+	case canaryv1alpha1.CommandRetry:
+		// rollback된 단계부터 다시 시작하여 이미 검증된 단계를 반복하지 않습니다.
+		if canary.Status.State != canaryv1alpha1.PhaseRolledBack || canary.Status.LastFailedStep == 0 {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is not rollbacked", cmd)
+		}
+		canary.Status.TraceID, canary.Status.SpanID = "", ""
+		canary.Status.StartTime = &metav1.Time{Time: r.now()}
+		result.startRun = tracing.Enabled()
+		canary.Status.CurrentStep = canary.Status.LastFailedStep
+		if canary.Status.CurrentStep > lastStep {
+			canary.Status.CurrentStep = lastStep
+		}
+		canary.Status.LastFailedStep = 0
+		canary.Status.State = canaryv1alpha1.PhasePromoting
+		result.event = canaryv1alpha1.NotificationStarted
+	case canaryv1alpha1.CommandStop:
+		if !prevActive {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is not running", cmd)
+		}
+		canary.Status.State = canaryv1alpha1.PhasePaused
+		canary.Status.Message = fmt.Sprintf("[%s] Canary is stopped by command.", r.now().Format(time.RFC3339))
+		result.cronDelete = true
+		result.event = canaryv1alpha1.NotificationPaused
+	case canaryv1alpha1.CommandCompletion:
+		if canary.Status.State == canaryv1alpha1.PhaseCompleted {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is already complete", cmd)
+		}
+		if err := reject(canaryv1alpha1.PhaseCompleted); err != nil {
+			return commandResult{}, err
+		}
+		canary.Status.State = canaryv1alpha1.PhaseCompleted
+		canary.Status.CurrentStep = lastStep
+		result.cronDelete = true
+		result.event = canaryv1alpha1.NotificationCompleted
+	case canaryv1alpha1.CommandPromote, canaryv1alpha1.CommandSkip:
+		// 다음 Cron 실행을 기다리지 않고 즉시 다음 단계로 진행합니다.
+		if !prevActive && !isIdle(canary) {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is not running or stopped", cmd)
+		}
+		if canary.Status.CurrentStep >= lastStep {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is already at the last step", cmd)
+		}
+		canary.Status.CurrentStep++
+	case canaryv1alpha1.CommandBack:
+		// 한 단계 이전으로 되돌립니다.
+		if !prevActive && !isIdle(canary) {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is not running or stopped", cmd)
+		}
+		if canary.Status.CurrentStep <= 0 {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is already at the first step", cmd)
+		}
+		canary.Status.CurrentStep--
+	case canaryv1alpha1.CommandSetStep:
+		// 지정한 단계로 즉시 이동합니다. 변경된 replicas는 다음 reconcile에서 동기화됩니다.
+		if !prevActive && !isIdle(canary) {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is not running or stopped", cmd)
+		}
+		if step == nil {
+			return commandResult{}, fmt.Errorf("command %q is rejected: step is required", cmd)
+		}
+		if *step < 0 || *step > lastStep {
+			return commandResult{}, fmt.Errorf("command %q is rejected: step %d is out of range 0-%d", cmd, *step, lastStep)
+		}
+		if *step == canary.Status.CurrentStep {
+			return commandResult{}, fmt.Errorf("command %q is rejected: canary is already at step %d", cmd, *step)
+		}
+		canary.Status.CurrentStep = *step
+	default:
+		return commandResult{}, fmt.Errorf("unknown command %q", cmd)
+	}
+
+	// 단계가 변경되거나 다시 시작되면 다음 단계 시간을 새로 계산합니다.
+	canary.Status.NextStepTime = nil
+	if canary.Status.CurrentStep != prevStep || (!prevActive && isActive(canary)) {
+		canary.Status.StepStartTime = &metav1.Time{Time: r.now()}
+	}
+
+	return result, nil
+}
+
+// finishCommand status patch가 성공한 뒤 runCommand의 후속 작업을 한 번만 실행합니다.
+func (r *CanaryReconciler) finishCommand(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	result commandResult,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	if result.cronDelete {
+		r.Cr.Delete(canary.Namespace, canary.Name)
+	}
+	if result.rollbackReason != "" {
+		recordRollback(canary.Namespace, canary.Name, result.rollbackReason)
+	}
+	if result.startRun {
+		if err := r.startRun(ctx, canary); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary trace", "namespace", canary.Namespace, "name", canary.Name)
+		}
+	}
+	if result.event != "" {
+		r.publish(ctx, canary, result.event, oldDeploy, newDeploy)
+	}
+}
+
+// startRun 새로운 Canary run의 root span을 생성하고 trace ID, span ID를 status에 저장합니다.
+// span이 sampling 되지 않아 trace ID가 없으면 저장하지 않습니다.
+func (r *CanaryReconciler) startRun(ctx context.Context, canary *canaryv1alpha1.Canary) error {
+	traceID, spanID := tracing.StartRun(ctx, tracing.CanaryAttributes(canary.Namespace, canary.Name)...)
+	if traceID == "" {
+		return nil
+	}
+
+	return patchStatus(ctx, r.Client, canary, func() bool {
+		canary.Status.TraceID, canary.Status.SpanID = traceID, spanID
+		return true
+	})
+}
+
+// now Clock이 설정되어 있으면 Clock의 시간을, 아니면 실제 시간을 반환합니다.
+func (r *CanaryReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+
+	return r.Clock.Now()
+}
+
+// apiReader cache를 거치지 않는 reader를 반환합니다.
+func (r *CanaryReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+
+	return r.APIReader
+}
+
+// rollback Canary를 롤백하고 전송할 lifecycle 이벤트를 반환합니다.
+// stepped 전략이면 RollingBack phase로 변경하고, 롤백이 완료될 때 이벤트를 전송하도록 빈 이벤트를 반환합니다.
+// 현재 phase에서 롤백할 수 없으면 status를 변경하지 않고 에러를 반환합니다.
+// patch 충돌 시 다시 실행되므로 Cron 제거는 호출한 곳에서 patch가 성공한 뒤에 실행합니다.
+func (r *CanaryReconciler) rollback(canary *canaryv1alpha1.Canary, reason string) (canaryv1alpha1.NotificationEvent, error) {
+	if isSteppedRollback(canary) && canary.Status.CurrentStep > 0 {
+		if err := canTransition(canary.Status.State, canaryv1alpha1.PhaseRollingBack); err != nil {
+			return "", err
+		}
+		canary.Status.LastFailedStep = canary.Status.CurrentStep
+		canary.Status.State = canaryv1alpha1.PhaseRollingBack
+		canary.Status.Message = fmt.Sprintf("[%s] Canary is rolling back%s.", r.now().Format(time.RFC3339), reason)
+		return "", nil
+	}
+
+	if err := canTransition(canary.Status.State, canaryv1alpha1.PhaseRolledBack); err != nil {
+		return "", err
+	}
+	// 이미 0 단계이면 retry에 사용할 실패한 단계를 덮어쓰지 않습니다.
+	if canary.Status.CurrentStep > 0 {
+		canary.Status.LastFailedStep = canary.Status.CurrentStep
+	}
+	canary.Status.CurrentStep = 0
+	canary.Status.State = canaryv1alpha1.PhaseRolledBack
+	canary.Status.Message = fmt.Sprintf("[%s] Canary is rollbacked%s.", r.now().Format(time.RFC3339), reason)
+	return canaryv1alpha1.NotificationRolledBack, nil
 }
 
 // toBeDeleted Canary 리소스 삭제 시 finalizer 제거
+// HPA를 원래 설정으로 되돌리지 못하면 finalizer를 남겨두고 에러를 반환하여 다시 시도합니다.
 func (r *CanaryReconciler) toBeDeleted(
 	ctx context.Context,
 	logger logr.Logger,
 	canary *canaryv1alpha1.Canary,
 	oldDeploy, newDeploy *appsv1.Deployment,
-) {
+) error {
 	if !controllerutil.ContainsFinalizer(canary, CanaryFinalizer) {
-		return
+		return nil
 	}
 
 	// oldDeployment, newDeployment owner reference 제거
 	logger.Info("[Reconcile] Performing Finalizer Operations for Canary before delete CR")
+	// 다른 controller가 추가한 Owner Reference를 지우지 않도록 resourceVersion을 확인하는 merge patch를 사용합니다.
+	oldBase := oldDeploy.DeepCopy()
 	if removeOwnerReference(oldDeploy, canary.UID) {
-		if err := r.Update(ctx, oldDeploy); err != nil {
+		if err := r.Patch(ctx, oldDeploy, client.MergeFromWithOptions(oldBase, client.MergeFromWithOptimisticLock{})); err != nil {
 			logger.Error(err, "[Reconcile] Failed to update oldDeployment delete owner reference", "namespace", canary.Namespace, "name", canary.Spec.OldDeployment)
 		}
 	}
+	newBase := newDeploy.DeepCopy()
 	if removeOwnerReference(newDeploy, canary.UID) {
-		if err := r.Update(ctx, newDeploy); err != nil {
+		if err := r.Patch(ctx, newDeploy, client.MergeFromWithOptions(newBase, client.MergeFromWithOptimisticLock{})); err != nil {
 			logger.Error(err, "[Reconcile] Failed to update newDeployment delete owner reference", "namespace", canary.Namespace, "name", canary.Spec.NewDeployment)
 		}
 	}
 
-	// Canary 리소스 finalizer 제거
-	if !controllerutil.RemoveFinalizer(canary, CanaryFinalizer) {
-		logger.Error(nil, "[Reconcile] Failed to remove finalizer from the Canary", "namespace", canary.Namespace, "name", canary.Name)
-		return
+	// 삭제된 Canary의 Cron 제거
+	r.Cr.Delete(canary.Namespace, canary.Name)
+
+	// Canary가 변경한 HPA 설정 복구
+	if canary.Spec.EnableHPA {
+		if err := r.restoreHPAs(ctx, oldDeploy, newDeploy); err != nil {
+			logger.Error(err, "[Reconcile] Failed to restore HorizontalPodAutoscalers", "namespace", canary.Namespace, "name", canary.Name)
+			return err
+		}
 	}
-	if err := r.Update(ctx, canary); err != nil {
+
+	// Canary 리소스 finalizer 제거
+	if err := patchMeta(ctx, r.Client, canary, func() bool {
+		return controllerutil.RemoveFinalizer(canary, CanaryFinalizer)
+	}); err != nil {
 		logger.Error(err, "[Reconcile] Failed to update Canary without finalizer", "namespace", canary.Namespace, "name", canary.Name)
+		return err
 	}
+
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -364,6 +905,7 @@ func (r *CanaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&canaryv1alpha1.Canary{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Complete(r)
 }
 
@@ -380,6 +922,31 @@ func isNotExists(oldDeploy, newDeploy *appsv1.Deployment) (string, bool) {
 	return message, message != ""
 }
 
+// isSteppedRollback stepped rollback 전략을 사용하는지 확인합니다.
+func isSteppedRollback(canary *canaryv1alpha1.Canary) bool {
+	return canary.Spec.Rollback != nil && canary.Spec.Rollback.Strategy == canaryv1alpha1.RollbackStepped
+}
+
+// rollbackInterval stepped rollback의 단계 간격을 반환합니다.
+func rollbackInterval(canary *canaryv1alpha1.Canary) time.Duration {
+	if canary.Spec.Rollback == nil || canary.Spec.Rollback.Interval == nil || canary.Spec.Rollback.Interval.Duration <= 0 {
+		return 30 * time.Second
+	}
+
+	return canary.Spec.Rollback.Interval.Duration
+}
+
+// parseStep step annotation 값을 단계로 변환합니다.
+func parseStep(value string) (*int32, error) {
+	step, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid step %q", value)
+	}
+
+	result := int32(step)
+	return &result, nil
+}
+
 // removeOwnerReference Owner Reference를 제거합니다.
 func removeOwnerReference(deploy *appsv1.Deployment, uid types.UID) bool {
 	if deploy.OwnerReferences == nil {
@@ -17,68 +17,145 @@ limitations under the License.
 package controller
 
 import (
-	"context"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
 )
 
 var _ = Describe("Canary Controller", func() {
-	Context("When reconciling a resource", func() {
-		const resourceName = "test-resource"
-
-		ctx := context.Background()
+	// action simulation에 적용하는 동작입니다.
+	type action func(s *simulation)
 
-		typeNamespacedName := types.NamespacedName{
-			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
-		}
-		canary := &canaryv1alpha1.Canary{}
-
-		BeforeEach(func() {
-			By("creating the custom resource for the Kind Canary")
-			err := k8sClient.Get(ctx, typeNamespacedName, canary)
-			if err != nil && errors.IsNotFound(err) {
-				resource := &canaryv1alpha1.Canary{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      resourceName,
-						Namespace: "default",
-					},
-					// TODO(user): Specify other spec details if needed.
-				}
-				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
-			}
-		})
-
-		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &canaryv1alpha1.Canary{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
-			Expect(err).NotTo(HaveOccurred())
-
-			By("Cleanup the specific resource instance Canary")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
-		})
-		It("should successfully reconcile the resource", func() {
-			By("Reconciling the created resource")
-			controllerReconciler := &CanaryReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+	apply := func(s *simulation) { s.command(canaryv1alpha1.CommandApply) }
+	command := func(cmd string) action {
+		return func(s *simulation) { s.command(cmd) }
+	}
+	setStep := func(step string) action {
+		return func(s *simulation) { s.commandStep(canaryv1alpha1.CommandSetStep, step) }
+	}
+	advance := func(n int) action {
+		return func(s *simulation) {
+			for i := 0; i < n; i++ {
+				s.advance(time.Minute)
 			}
+		}
+	}
+	crash := func(s *simulation) { s.crash() }
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
+	DescribeTable("state transitions",
+		func(enableRollback bool, actions []action, state canaryv1alpha1.CanaryPhase, step, oldReplicas, newReplicas int32) {
+			name := "transition-" + strings.ToLower(strings.ReplaceAll(CurrentSpecReport().LeafNodeText, " ", "-"))
+			s := newSimulation(name, canaryv1alpha1.CanarySpec{
+				TotalReplicas:  10,
+				StepReplicas:   2,
+				CronSchedule:   "* * * * *",
+				EnableRollback: enableRollback,
 			})
-			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
-		})
+			for _, act := range actions {
+				act(s)
+			}
+
+			s.expect(state, step, oldReplicas, newReplicas)
+			canary := s.canary()
+			Expect(canary.Status.OldReplicas).To(Equal(oldReplicas))
+			Expect(canary.Status.NewReplicas).To(Equal(newReplicas))
+		},
+		Entry("pending", false, nil, canaryv1alpha1.PhasePending, int32(0), int32(10), int32(0)),
+		Entry("apply", false, []action{apply}, canaryv1alpha1.PhaseProgressing, int32(0), int32(10), int32(0)),
+		Entry("step advance", false, []action{apply, advance(1)}, canaryv1alpha1.PhaseProgressing, int32(1), int32(8), int32(2)),
+		Entry("pending does not advance", false, []action{advance(2)}, canaryv1alpha1.PhasePending, int32(0), int32(10), int32(0)),
+		Entry("completion", false, []action{apply, advance(5)}, canaryv1alpha1.PhaseCompleted, int32(5), int32(0), int32(10)),
+		Entry("completion stays complete", false, []action{apply, advance(7)}, canaryv1alpha1.PhaseCompleted, int32(5), int32(0), int32(10)),
+		Entry("completion command", false, []action{apply, command(canaryv1alpha1.CommandCompletion)}, canaryv1alpha1.PhaseCompleted, int32(5), int32(0), int32(10)),
+		Entry("stop", false, []action{apply, advance(1), command(canaryv1alpha1.CommandStop), advance(2)}, canaryv1alpha1.PhasePaused, int32(1), int32(8), int32(2)),
+		Entry("resume", false, []action{apply, advance(1), command(canaryv1alpha1.CommandStop), command(canaryv1alpha1.CommandApply), advance(1)}, canaryv1alpha1.PhaseProgressing, int32(2), int32(6), int32(4)),
+		Entry("promote", false, []action{apply, command(canaryv1alpha1.CommandPromote)}, canaryv1alpha1.PhaseProgressing, int32(1), int32(8), int32(2)),
+		Entry("back", false, []action{apply, advance(2), command(canaryv1alpha1.CommandBack)}, canaryv1alpha1.PhaseProgressing, int32(1), int32(8), int32(2)),
+		Entry("setstep", false, []action{apply, setStep("4")}, canaryv1alpha1.PhaseProgressing, int32(4), int32(2), int32(8)),
+		Entry("rollback command", false, []action{apply, advance(3), command(canaryv1alpha1.CommandRollback)}, canaryv1alpha1.PhaseRolledBack, int32(0), int32(10), int32(0)),
+		Entry("retry", false, []action{apply, advance(3), command(canaryv1alpha1.CommandRollback), command(canaryv1alpha1.CommandRetry)}, canaryv1alpha1.PhaseProgressing, int32(3), int32(4), int32(6)),
+		Entry("crash rollback", true, []action{apply, advance(2), crash}, canaryv1alpha1.PhaseRolledBack, int32(0), int32(10), int32(0)),
+		Entry("crash without rollback", false, []action{apply, advance(2), crash}, canaryv1alpha1.PhaseProgressing, int32(2), int32(6), int32(4)),
+	)
+
+	It("should show the pending message before the apply command", func() {
+		s := newSimulation("canary-pending", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"})
+		canary := s.canary()
+		Expect(canary.Status.Message).To(Equal("Canary is Pending"))
+		Expect(canary.Finalizers).To(ContainElement(CanaryFinalizer))
+	})
+
+	It("should reject a command with the status message", func() {
+		s := newSimulation("canary-rejected", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"})
+		s.command(canaryv1alpha1.CommandStop)
+		canary := s.canary()
+		Expect(canary.Status.Message).To(ContainSubstring("canary is not running"))
+		Expect(canary.Annotations).NotTo(HaveKey(Command))
+	})
+
+	It("should fail when a deployment is missing", func() {
+		s := newSimulation("canary-missing", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"})
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+
+		s.deleteDeployment(s.deploys[1])
+		s.reconcile()
+		canary := s.canary()
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseFailed))
+		Expect(canary.Status.Message).To(ContainSubstring("New deployment not found"))
+		Expect(canary.Status.OldReplicas).To(BeZero())
+		Expect(canary.Status.NewReplicas).To(BeZero())
+		Expect(cronNext(s.cron, s.key.Namespace, s.key.Name)).To(BeNil())
+
+		// Deployment가 다시 생성되면 실패한 단계부터 다시 진행합니다.
+		s.createDeployment(s.deploys[1], 2)
+		s.reconcile()
+		s.expect(canaryv1alpha1.PhaseProgressing, 1, 8, 2)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 2, 6, 4)
+	})
+
+	It("should wait for deployments created after the canary", func() {
+		s := newCanarySimulation("canary-before-deployments", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"}, nil)
+		s.reconcile()
+		Expect(s.canary().Status.State).To(Equal(canaryv1alpha1.PhaseFailed))
+
+		s.createDeployments(10)
+		s.reconcile()
+		s.expect(canaryv1alpha1.PhasePending, 0, 10, 0)
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 1, 8, 2)
+	})
+
+	It("should apply a failed canary", func() {
+		s := newCanarySimulation("canary-failed-apply", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"}, nil)
+		s.reconcile()
+		canary := s.canary()
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseFailed))
+
+		_, err := s.reconciler.runCommand(canary, canaryv1alpha1.CommandApply, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+		Expect(canary.Status.StartTime).NotTo(BeNil())
+	})
+
+	It("should remove the owner references of the deployments on deletion", func() {
+		s := newSimulation("canary-deletion", canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"})
+		s.command(canaryv1alpha1.CommandApply)
+		canary := s.canary()
+		for _, name := range s.deploys {
+			Expect(s.deployment(name).OwnerReferences).To(ContainElement(HaveField("UID", canary.UID)))
+		}
+
+		s.deleteCanary()
+		for _, name := range s.deploys {
+			Expect(s.deployment(name).OwnerReferences).To(BeEmpty())
+		}
+		Expect(cronNext(s.cron, s.key.Namespace, s.key.Name)).To(BeNil())
 	})
 })
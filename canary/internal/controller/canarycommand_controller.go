@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/tracing"
+)
+
+// CanaryCommandReconciler reconciles a CanaryCommand object
+type CanaryCommandReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Canary Command를 Canary에 적용하는 Canary reconciler
+	Canary *CanaryReconciler
+}
+
+//+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=canarycommands,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=canarycommands/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=canarycommands/finalizers,verbs=update
+
+// Reconcile CanaryCommand를 한 번만 처리하고 결과를 status에 기록합니다.
+func (r *CanaryCommandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	command := &canaryv1alpha1.CanaryCommand{}
+	if err := r.Get(ctx, req.NamespacedName, command); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// 이미 처리된 Command는 다시 처리하지 않습니다.
+	if command.Status.Phase != "" {
+		return ctrl.Result{}, nil
+	}
+
+	canary := &canaryv1alpha1.Canary{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: command.Spec.CanaryName}, canary); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.finish(ctx, command, nil, canaryv1alpha1.CanaryCommandRejected, "Canary not found")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Command 이력이 Canary와 함께 삭제되도록 owner reference를 추가합니다.
+	if !hasOwnerReference(command, canary) {
+		if err := controllerutil.SetOwnerReference(canary, command, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.Update(ctx, command)
+	}
+
+	ctx, span := tracing.Start(ctx, canary.Status.TraceID, canary.Status.SpanID, "CanaryCommand", tracing.CanaryAttributes(canary.Namespace, canary.Name)...)
+	defer span.End()
+
+	// Canary status 충돌 시 최신 Canary에 Command를 다시 적용합니다.
+	// Command UID를 같은 patch로 Canary status에 기록하여, Command status 저장이 실패하거나
+	// 오래된 cache로 다시 reconcile되어도 Command를 두 번 적용하지 않습니다.
+	var result commandResult
+	var cmdErr error
+	applied := false
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		result, cmdErr = commandResult{}, nil
+		if applied = isCommandApplied(canary, command.UID); applied {
+			return false
+		}
+		result, cmdErr = r.Canary.runCommand(canary, command.Spec.Command, command.Spec.Step)
+		if cmdErr != nil {
+			return false
+		}
+		recordCommand(canary, command.UID)
+		return true
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	if applied {
+		logger.Info("[CanaryCommand] Command is already applied", "namespace", req.Namespace, "name", req.Name)
+		return ctrl.Result{}, r.finish(ctx, command, canary, canaryv1alpha1.CanaryCommandSucceeded,
+			fmt.Sprintf("Command %s is applied", command.Spec.Command))
+	}
+	if cmdErr != nil {
+		logger.Info("[CanaryCommand] Command is rejected", "namespace", req.Namespace, "name", req.Name, "reason", cmdErr.Error())
+		return ctrl.Result{}, r.finish(ctx, command, canary, canaryv1alpha1.CanaryCommandRejected, cmdErr.Error())
+	}
+
+	oldDeploy, newDeploy := &appsv1.Deployment{}, &appsv1.Deployment{}
+	if result.event != "" {
+		_ = r.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Spec.OldDeployment}, oldDeploy)
+		_ = r.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Spec.NewDeployment}, newDeploy)
+	}
+	r.Canary.finishCommand(ctx, logger, canary, result, oldDeploy, newDeploy)
+
+	logger.Info("[CanaryCommand] Command is applied", "namespace", req.Namespace, "name", req.Name,
+		"canary", canary.Name, "command", command.Spec.Command, "issuedBy", command.Spec.IssuedBy)
+	return ctrl.Result{}, r.finish(ctx, command, canary, canaryv1alpha1.CanaryCommandSucceeded,
+		fmt.Sprintf("Command %s is applied", command.Spec.Command))
+}
+
+// finish Command 처리 결과를 status에 기록합니다.
+func (r *CanaryCommandReconciler) finish(
+	ctx context.Context,
+	command *canaryv1alpha1.CanaryCommand,
+	canary *canaryv1alpha1.Canary,
+	phase canaryv1alpha1.CanaryCommandPhase,
+	message string,
+) error {
+	now := metav1.NewTime(r.Canary.now())
+	command.Status.Phase = phase
+	command.Status.Message = message
+	command.Status.ProcessedAt = &now
+	if canary != nil {
+		command.Status.State = canary.Status.State
+		command.Status.CurrentStep = canary.Status.CurrentStep
+	}
+
+	return r.Status().Update(ctx, command)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CanaryCommandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&canaryv1alpha1.CanaryCommand{}).
+		Complete(r)
+}
+
+// maxAppliedCommands Canary status에 기록하는 최근 적용된 Command의 수
+const maxAppliedCommands = 10
+
+// isCommandApplied Command가 이미 Canary에 적용되었는지 확인합니다.
+func isCommandApplied(canary *canaryv1alpha1.Canary, uid types.UID) bool {
+	for _, applied := range canary.Status.AppliedCommands {
+		if applied == uid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordCommand 적용된 Command UID를 Canary status에 기록하고 오래된 UID는 제거합니다.
+func recordCommand(canary *canaryv1alpha1.Canary, uid types.UID) {
+	canary.Status.AppliedCommands = append(canary.Status.AppliedCommands, uid)
+	if n := len(canary.Status.AppliedCommands); n > maxAppliedCommands {
+		canary.Status.AppliedCommands = canary.Status.AppliedCommands[n-maxAppliedCommands:]
+	}
+}
+
+// hasOwnerReference owner의 owner reference가 있는지 확인합니다.
+func hasOwnerReference(obj, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("CanaryCommand Controller", func() {
+	Context("When reconciling a resource", func() {
+		const canaryName = "command-canary"
+
+		ctx := context.Background()
+		var controllerReconciler *CanaryCommandReconciler
+
+		reconcileCommand := func(name string) *canaryv1alpha1.CanaryCommand {
+			key := types.NamespacedName{Namespace: "default", Name: name}
+			// owner reference 추가 후 Command를 처리합니다.
+			for i := 0; i < 2; i++ {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			command := &canaryv1alpha1.CanaryCommand{}
+			Expect(k8sClient.Get(ctx, key, command)).To(Succeed())
+			return command
+		}
+
+		createStepCommand := func(name, canary, cmd string, step *int32) {
+			Expect(k8sClient.Create(ctx, &canaryv1alpha1.CanaryCommand{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+				Spec:       canaryv1alpha1.CanaryCommandSpec{CanaryName: canary, Command: cmd, Step: step},
+			})).To(Succeed())
+		}
+
+		createCommand := func(name, canary, cmd string) {
+			createStepCommand(name, canary, cmd, nil)
+		}
+
+		step := func(step int32) *int32 {
+			return &step
+		}
+
+		BeforeEach(func() {
+			canaryReconciler, _ := newTestReconciler()
+			controllerReconciler = &CanaryCommandReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Canary: canaryReconciler,
+			}
+
+			createTestCanary(ctx, metav1.ObjectMeta{Name: canaryName}, testCanarySpec(), nil)
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &canaryv1alpha1.CanaryCommand{}, client.InNamespace("default"))).To(Succeed())
+		})
+
+		It("should apply the command to the Canary and record the result", func() {
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+
+			command := reconcileCommand("apply-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+			Expect(command.Status.ProcessedAt).NotTo(BeNil())
+			Expect(command.Status.ProcessedAt.Time).To(BeTemporally("==", testStart))
+			Expect(command.OwnerReferences).To(HaveLen(1))
+
+			canary := &canaryv1alpha1.Canary{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: canaryName}, canary)).To(Succeed())
+			Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+		})
+
+		It("should reject a command not allowed in the current state", func() {
+			createCommand("stop-command", canaryName, canaryv1alpha1.CommandStop)
+
+			command := reconcileCommand("stop-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandRejected))
+			Expect(command.Status.Message).To(ContainSubstring("not running"))
+		})
+
+		It("should move the Canary to the given step and back", func() {
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+			Expect(reconcileCommand("apply-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createStepCommand("setstep-command", canaryName, canaryv1alpha1.CommandSetStep, step(3))
+			command := reconcileCommand("setstep-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.CurrentStep).To(Equal(int32(3)))
+
+			createCommand("back-command", canaryName, canaryv1alpha1.CommandBack)
+			command = reconcileCommand("back-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.CurrentStep).To(Equal(int32(2)))
+
+			createCommand("skip-command", canaryName, canaryv1alpha1.CommandSkip)
+			command = reconcileCommand("skip-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.CurrentStep).To(Equal(int32(3)))
+		})
+
+		It("should not apply a command again when its result was not recorded", func() {
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+			Expect(reconcileCommand("apply-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createCommand("promote-command", canaryName, canaryv1alpha1.CommandPromote)
+			command := reconcileCommand("promote-command")
+			Expect(command.Status.CurrentStep).To(Equal(int32(1)))
+
+			// Command status 저장이 실패한 것처럼 결과를 지우고 다시 reconcile합니다.
+			command.Status = canaryv1alpha1.CanaryCommandStatus{}
+			Expect(k8sClient.Status().Update(ctx, command)).To(Succeed())
+			command = reconcileCommand("promote-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.CurrentStep).To(Equal(int32(1)))
+
+			canary := &canaryv1alpha1.Canary{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: canaryName}, canary)).To(Succeed())
+			Expect(canary.Status.CurrentStep).To(Equal(int32(1)))
+			Expect(canary.Status.AppliedCommands).To(ContainElement(command.UID))
+		})
+
+		It("should reject a step outside of the step plan", func() {
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+			Expect(reconcileCommand("apply-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createStepCommand("setstep-command", canaryName, canaryv1alpha1.CommandSetStep, step(6))
+			command := reconcileCommand("setstep-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandRejected))
+			Expect(command.Status.Message).To(ContainSubstring("out of range 0-5"))
+
+			createCommand("back-command", canaryName, canaryv1alpha1.CommandBack)
+			command = reconcileCommand("back-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandRejected))
+			Expect(command.Status.Message).To(ContainSubstring("first step"))
+		})
+
+		It("should retry a rolled back Canary from the failed step", func() {
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+			Expect(reconcileCommand("apply-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createCommand("retry-running-command", canaryName, canaryv1alpha1.CommandRetry)
+			Expect(reconcileCommand("retry-running-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandRejected))
+
+			createStepCommand("setstep-command", canaryName, canaryv1alpha1.CommandSetStep, step(3))
+			Expect(reconcileCommand("setstep-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createCommand("rollback-command", canaryName, canaryv1alpha1.CommandRollback)
+			Expect(reconcileCommand("rollback-command").Status.CurrentStep).To(Equal(int32(0)))
+
+			createCommand("retry-command", canaryName, canaryv1alpha1.CommandRetry)
+			command := reconcileCommand("retry-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+			Expect(command.Status.CurrentStep).To(Equal(int32(3)))
+
+			canary := &canaryv1alpha1.Canary{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: canaryName}, canary)).To(Succeed())
+			Expect(canary.Status.LastFailedStep).To(BeZero())
+		})
+
+		It("should roll back step by step with the stepped strategy", func() {
+			canary := &canaryv1alpha1.Canary{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: canaryName}, canary)).To(Succeed())
+			canary.Spec.Rollback = &canaryv1alpha1.RollbackSpec{Strategy: canaryv1alpha1.RollbackStepped}
+			Expect(k8sClient.Update(ctx, canary)).To(Succeed())
+
+			createCommand("apply-command", canaryName, canaryv1alpha1.CommandApply)
+			Expect(reconcileCommand("apply-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			createStepCommand("setstep-command", canaryName, canaryv1alpha1.CommandSetStep, step(3))
+			Expect(reconcileCommand("setstep-command").Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+
+			createCommand("rollback-command", canaryName, canaryv1alpha1.CommandRollback)
+			command := reconcileCommand("rollback-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.State).To(Equal(canaryv1alpha1.PhaseRollingBack))
+			Expect(command.Status.CurrentStep).To(Equal(int32(3)))
+
+			// 단계별 롤백 중 rollback Command는 롤백을 즉시 완료합니다.
+			createCommand("rollback-now-command", canaryName, canaryv1alpha1.CommandRollback)
+			command = reconcileCommand("rollback-now-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandSucceeded))
+			Expect(command.Status.State).To(Equal(canaryv1alpha1.PhaseRolledBack))
+			Expect(command.Status.CurrentStep).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: canaryName}, canary)).To(Succeed())
+			Expect(canary.Status.LastFailedStep).To(Equal(int32(3)))
+		})
+
+		It("should reject a command for a missing Canary", func() {
+			createCommand("missing-command", "missing-canary", canaryv1alpha1.CommandApply)
+
+			command := reconcileCommand("missing-command")
+			Expect(command.Status.Phase).To(Equal(canaryv1alpha1.CanaryCommandRejected))
+			Expect(command.Status.Message).To(Equal("Canary not found"))
+		})
+	})
+})
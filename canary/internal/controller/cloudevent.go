@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
+)
+
+// canaryEventData CloudEvent로 전송할 Canary 정보를 구성합니다.
+func canaryEventData(canary *canaryv1alpha1.Canary, oldDeploy, newDeploy *appsv1.Deployment) cloudevent.Data {
+	oldReplicas, newReplicas := desiredReplicas(canary)
+	return cloudevent.Data{
+		Namespace:   canary.Namespace,
+		Name:        canary.Name,
+		Step:        canary.Status.CurrentStep,
+		OldReplicas: oldReplicas,
+		NewReplicas: newReplicas,
+		OldImages:   deploymentImages(oldDeploy),
+		NewImages:   deploymentImages(newDeploy),
+		State:       string(canary.Status.State),
+		Message:     canary.Status.Message,
+	}
+}
+
+// emit Canary lifecycle CloudEvent를 전송합니다.
+func emit(
+	ctx context.Context,
+	emitter *cloudevent.Emitter,
+	eventType cloudevent.Type,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	if emitter == nil {
+		return
+	}
+	emitter.Emit(ctx, eventType, canaryEventData(canary, oldDeploy, newDeploy))
+}
+
+// emitAnalysis new deployment 상태 분석 결과 CloudEvent를 전송합니다.
+func emitAnalysis(
+	ctx context.Context,
+	emitter *cloudevent.Emitter,
+	result string,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	if emitter == nil {
+		return
+	}
+	data := canaryEventData(canary, oldDeploy, newDeploy)
+	data.AnalysisResult = result
+	emitter.Emit(ctx, cloudevent.TypeAnalysisResult, data)
+}
+
+// deploymentImages Deployment의 container image 목록을 반환합니다.
+func deploymentImages(deploy *appsv1.Deployment) []string {
+	if deploy == nil {
+		return nil
+	}
+
+	images := make([]string, 0, len(deploy.Spec.Template.Spec.Containers))
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+
+	return images
+}
+
+// lifecycleEventTypes 알림 이벤트에 대응하는 CloudEvent type입니다.
+var lifecycleEventTypes = map[canaryv1alpha1.NotificationEvent]cloudevent.Type{
+	canaryv1alpha1.NotificationStarted:    cloudevent.TypeStarted,
+	canaryv1alpha1.NotificationPaused:     cloudevent.TypePaused,
+	canaryv1alpha1.NotificationCompleted:  cloudevent.TypeCompleted,
+	canaryv1alpha1.NotificationRolledBack: cloudevent.TypeRolledBack,
+}
+
+// publish lifecycle 이벤트를 webhook 알림과 CloudEvent로 전송합니다.
+func (r *CanaryReconciler) publish(
+	ctx context.Context,
+	canary *canaryv1alpha1.Canary,
+	event canaryv1alpha1.NotificationEvent,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	r.notify(ctx, canary, event)
+	emit(ctx, r.Emitter, lifecycleEventTypes[event], canary, oldDeploy, newDeploy)
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
+)
+
+var _ = Describe("CloudEvent", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var (
+		emitter *cloudevent.Emitter
+		mu      sync.Mutex
+		events  []map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		events = nil
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			raw, _ := io.ReadAll(req.Body)
+			body := map[string]interface{}{}
+			_ = json.Unmarshal(raw, &body)
+
+			mu.Lock()
+			events = append(events, body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		DeferCleanup(sink.Close)
+
+		var err error
+		emitter, err = cloudevent.NewEmitter(cloudevent.Options{Sink: sink.URL})
+		Expect(err).NotTo(HaveOccurred())
+		emitterCtx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		go func() {
+			defer GinkgoRecover()
+			Expect(emitter.Start(emitterCtx)).To(Succeed())
+		}()
+	})
+
+	// receivedTypes 수신한 CloudEvent의 type과 analysisResult를 반환합니다.
+	receivedTypes := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		types := make([]string, 0, len(events))
+		for _, ev := range events {
+			eventType, _ := ev["type"].(string)
+			if data, ok := ev["data"].(map[string]interface{}); ok && data["analysisResult"] != nil {
+				eventType += "/" + data["analysisResult"].(string)
+			}
+			types = append(types, eventType)
+		}
+
+		return types
+	}
+
+	It("should emit the healthy analysis result before the step event", func() {
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "cloudevent-healthy"}, testCanarySpec(), &canaryv1alpha1.CanaryStatus{
+			State:       canaryv1alpha1.PhaseProgressing,
+			CurrentStep: 1,
+		})
+
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, emitter, canary, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Eventually(receivedTypes).Should(Equal([]string{
+			string(cloudevent.TypeAnalysisResult) + "/" + AnalysisResultHealthy,
+			string(cloudevent.TypeStep),
+		}))
+	})
+
+	It("should emit the deadline analysis result when the deadline is exceeded", func() {
+		reconciler, fakeClock := newTestReconciler()
+		reconciler.Emitter = emitter
+		spec := testCanarySpec()
+		spec.ProgressDeadline = &metav1.Duration{Duration: 10 * time.Minute}
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "cloudevent-deadline"}, spec, &canaryv1alpha1.CanaryStatus{
+			State:         canaryv1alpha1.PhaseProgressing,
+			CurrentStep:   2,
+			StartTime:     &metav1.Time{Time: testStart},
+			StepStartTime: &metav1.Time{Time: testStart},
+		})
+
+		fakeClock.Step(time.Hour)
+		exceeded, _ := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 2))
+		Expect(exceeded).To(BeTrue())
+		Eventually(receivedTypes).Should(ContainElement(string(cloudevent.TypeAnalysisResult) + "/" + AnalysisResultDeadline))
+	})
+})
@@ -2,24 +2,44 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
+	"github.com/k8shuginn/canary-operator/internal/tracing"
 	cronv3 "github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sort"
+	"sync"
 	"time"
 )
 
-var (
-	k8sClient client.Client
-)
-
 type CronJob struct {
+	client    client.Client
+	reader    client.Reader
+	emitter   *cloudevent.Emitter
 	id        cronv3.EntryID
 	schedule  string
 	namespace string
 	name      string
 
 	old, new string
+
+	// rollback 단계를 증가하는 대신 감소시키는 stepped rollback job
+	rollback bool
+
+	// sched 다음 실행 시간을 계산하기 위한 파싱된 schedule
+	sched cronv3.Schedule
+
+	// clock step 변경 시간을 기록하는 clock
+	clock clock.PassiveClock
+
+	// next manual Cron에서 job을 실행할 시간
+	next time.Time
 }
 
 func (j *CronJob) Run() {
@@ -27,17 +47,31 @@ func (j *CronJob) Run() {
 	logger := log.FromContext(ctx)
 
 	canary := &v1alpha1.Canary{}
-	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: j.namespace, Name: j.name}, canary); err != nil {
+	if err := j.client.Get(ctx, client.ObjectKey{Namespace: j.namespace, Name: j.name}, canary); err != nil {
 		logger.Error(err, "[Cron] Failed to get Canary")
 		return
 	}
 
-	if canary.Status.CurrentStep < canary.Spec.TotalReplicas/canary.Spec.StepReplicas {
-		canary.Status.CurrentStep++
-		_ = k8sClient.Status().Update(ctx, canary)
+	ctx, span := tracing.Start(ctx, canary.Status.TraceID, canary.Status.SpanID, "CronJob.Run", tracing.CanaryAttributes(j.namespace, j.name)...)
+	defer span.End()
+	span.SetAttributes(attribute.Int("step", int(canary.Status.CurrentStep)))
+
+	if j.rollback {
+		j.stepBack(ctx, canary)
+		return
+	}
 
-		canary.Annotations[AnnotationLastUpdate] = time.Now().Format(time.RFC3339)
-		if err := k8sClient.Update(context.Background(), canary); err != nil {
+	now := j.clock.Now()
+	if ok, err := advanceStep(ctx, j.client, j.reader, j.emitter, canary, now); err != nil {
+		span.RecordError(err)
+		logger.Error(err, "[Cron] Failed to update Canary status")
+		return
+	} else if ok {
+		if err := patchMeta(ctx, j.client, canary, func() bool {
+			setAnnotation(canary, AnnotationLastUpdate, now.Format(time.RFC3339))
+			return true
+		}); err != nil {
+			span.RecordError(err)
 			logger.Error(err, "[Cron] Failed to update Canary")
 			return
 		}
@@ -45,63 +79,232 @@ func (j *CronJob) Run() {
 	logger.Info("[Cron] Updated Canary", "namespace", j.namespace, "name", j.name)
 }
 
+// stepBack stepped rollback 중인 Canary를 한 단계 이전으로 되돌립니다.
+// old Deployment가 모두 available 상태일 때만 진행하여 가용성을 유지합니다.
+func (j *CronJob) stepBack(ctx context.Context, canary *v1alpha1.Canary) {
+	logger := log.FromContext(ctx)
+	if canary.Status.State != v1alpha1.PhaseRollingBack || canary.Status.CurrentStep == 0 {
+		return
+	}
+
+	oldDeploy := &appsv1.Deployment{}
+	if err := j.client.Get(ctx, client.ObjectKey{Namespace: j.namespace, Name: j.old}, oldDeploy); err != nil {
+		logger.Error(err, "[Cron] Failed to get oldDeployment")
+		return
+	}
+	if oldDeploy.Spec.Replicas != nil && oldDeploy.Status.AvailableReplicas < *oldDeploy.Spec.Replicas {
+		logger.Info("[Cron] Waiting for oldDeployment to be available", "namespace", j.namespace, "name", j.name)
+		return
+	}
+
+	// 동시에 rollback Command로 롤백이 완료되면 단계를 변경하지 않습니다.
+	stepped := false
+	if err := patchStatus(ctx, j.client, canary, func() bool {
+		stepped = canary.Status.State == v1alpha1.PhaseRollingBack && canary.Status.CurrentStep > 0
+		if stepped {
+			canary.Status.CurrentStep--
+		}
+		return stepped
+	}); err != nil {
+		logger.Error(err, "[Cron] Failed to update Canary status")
+		return
+	}
+	if !stepped {
+		return
+	}
+	emitStep(ctx, j.client, j.emitter, canary, "")
+
+	if err := patchMeta(ctx, j.client, canary, func() bool {
+		setAnnotation(canary, AnnotationLastUpdate, j.clock.Now().Format(time.RFC3339))
+		return true
+	}); err != nil {
+		logger.Error(err, "[Cron] Failed to update Canary")
+		return
+	}
+	logger.Info("[Cron] Rolled back Canary one step", "namespace", j.namespace, "name", j.name)
+}
+
+// setAnnotation annotation이 없는 Canary에도 annotation을 기록합니다.
+func setAnnotation(canary *v1alpha1.Canary, key, value string) {
+	if canary.Annotations == nil {
+		canary.Annotations = map[string]string{}
+	}
+	canary.Annotations[key] = value
+}
+
+// cronAction status patch가 성공한 뒤 Canary의 Cron job에 적용할 변경입니다.
+type cronAction int
+
+const (
+	// cronDelete 등록된 job을 제거합니다.
+	cronDelete cronAction = iota
+	// cronApply 단계를 진행하는 job을 등록합니다.
+	cronApply
+	// cronApplyRollback 한 단계씩 되돌리는 stepped rollback job을 등록합니다.
+	cronApplyRollback
+)
+
+// Cron Canary별 단계 진행 job을 관리합니다.
+// Canary, CanaryCommand controller가 서로 다른 goroutine에서 호출하므로 idMap은 mu로 보호합니다.
 type Cron struct {
 	client.Client
-	cr    *cronv3.Cron
-	idMap map[string]*CronJob
+	reader  client.Reader
+	cr      *cronv3.Cron
+	mu      sync.Mutex
+	idMap   map[string]*CronJob
+	emitter *cloudevent.Emitter
+	clock   clock.PassiveClock
 }
 
-func NewCron(client client.Client) *Cron {
+// NewCron Cron을 생성합니다. holidays ConfigMap은 cache를 거치지 않는 reader로 읽습니다.
+func NewCron(client client.Client, reader client.Reader, emitter *cloudevent.Emitter) *Cron {
 	cr := cronv3.New()
 	c := &Cron{
-		Client: client,
-		cr:     cr,
-		idMap:  make(map[string]*CronJob),
+		Client:  client,
+		reader:  reader,
+		cr:      cr,
+		idMap:   make(map[string]*CronJob),
+		emitter: emitter,
+		clock:   clock.RealClock{},
 	}
 	c.cr.Start()
 
 	return c
 }
 
+// NewManualCron robfig scheduler 없이 clock으로 실행 시간을 계산하는 Cron을 생성합니다.
+// job은 RunDue를 호출할 때만 실행되므로 테스트에서 단계 진행을 실제 시간과 관계없이 재현할 수 있습니다.
+func NewManualCron(client client.Client, reader client.Reader, emitter *cloudevent.Emitter, clk clock.PassiveClock) *Cron {
+	return &Cron{
+		Client:  client,
+		reader:  reader,
+		idMap:   make(map[string]*CronJob),
+		emitter: emitter,
+		clock:   clk,
+	}
+}
+
+// RunDue manual Cron에서 실행 시간이 지난 job을 이름 순서로 한 번씩 실행하고 실행한 job 수를 반환합니다.
+func (c *Cron) RunDue() int {
+	now := c.clock.Now()
+	var jobs []*CronJob
+	c.mu.Lock()
+	for _, job := range c.idMap {
+		if !job.next.After(now) {
+			job.next = job.sched.Next(now)
+			jobs = append(jobs, job)
+		}
+	}
+	c.mu.Unlock()
+	sort.Slice(jobs, func(i, k int) bool {
+		return makeIndex(jobs[i].namespace, jobs[i].name) < makeIndex(jobs[k].namespace, jobs[k].name)
+	})
+
+	for _, job := range jobs {
+		job.Run()
+	}
+	return len(jobs)
+}
+
 func (c *Cron) Apply(
 	namespace, name, spec string,
 	old, new string,
 ) error {
+	return c.apply(namespace, name, spec, old, new, false)
+}
+
+// ApplyRollback interval마다 한 단계씩 되돌리는 stepped rollback job을 등록합니다.
+func (c *Cron) ApplyRollback(
+	namespace, name string,
+	interval time.Duration,
+	old, new string,
+) error {
+	return c.apply(namespace, name, "@every "+interval.String(), old, new, true)
+}
+
+func (c *Cron) apply(
+	namespace, name, spec string,
+	old, new string,
+	rollback bool,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	idx := makeIndex(namespace, name)
 	if info, ok := c.idMap[idx]; ok {
-		if info.schedule == spec && info.old == old && info.new == new {
+		if info.schedule == spec && info.old == old && info.new == new && info.rollback == rollback {
 			return nil
 		}
-		c.cr.Remove(info.id)
+		c.remove(info)
 		delete(c.idMap, idx)
 	}
 
+	sched, err := cronv3.ParseStandard(spec)
+	if err != nil {
+		return err
+	}
+
 	cj := &CronJob{
+		client:    c.Client,
+		reader:    c.reader,
+		emitter:   c.emitter,
+		sched:     sched,
 		schedule:  spec,
 		namespace: namespace,
 		name:      name,
 		old:       old,
 		new:       new,
+		rollback:  rollback,
+		clock:     c.clock,
+		next:      sched.Next(c.clock.Now()),
 	}
 
-	id, err := c.cr.AddJob(spec, cj)
-	if err != nil {
-		return err
+	if c.cr != nil {
+		cj.id = c.cr.Schedule(sched, cj)
 	}
-	cj.id = id
 	c.idMap[idx] = cj
 
 	return nil
 }
 
 func (c *Cron) Delete(namespace, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	idx := makeIndex(namespace, name)
 	if info, ok := c.idMap[idx]; ok {
-		c.cr.Remove(info.id)
+		c.remove(info)
 		delete(c.idMap, idx)
 	}
 }
 
+// remove robfig scheduler에 등록된 job을 제거합니다.
+func (c *Cron) remove(job *CronJob) {
+	if c.cr != nil {
+		c.cr.Remove(job.id)
+	}
+}
+
+// validateCronSchedule Cron이 사용할 수 있는 schedule인지 확인합니다.
+func validateCronSchedule(spec string) error {
+	if spec == "" {
+		return errors.New("cronSchedule is empty")
+	}
+	_, err := cronv3.ParseStandard(spec)
+	return err
+}
+
+// nextCronTime schedule의 now 이후 다음 실행 시간을 반환합니다. schedule을 파싱할 수 없으면 nil을 반환합니다.
+func nextCronTime(spec string, now time.Time) *metav1.Time {
+	sched, err := cronv3.ParseStandard(spec)
+	if err != nil {
+		return nil
+	}
+
+	next := metav1.NewTime(sched.Next(now))
+	return &next
+}
+
 func makeIndex(namespace, name string) string {
 	return namespace + "/" + name
 }
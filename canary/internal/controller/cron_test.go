@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// cronNext Canary의 다음 Cron 실행 시간을 반환합니다. 등록된 job이 없으면 nil을 반환합니다.
+func cronNext(c *Cron, namespace, name string) *metav1.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	job, ok := c.idMap[makeIndex(namespace, name)]
+	if !ok {
+		return nil
+	}
+
+	next := metav1.NewTime(job.sched.Next(c.clock.Now()))
+	return &next
+}
+
+var _ = Describe("Cron", func() {
+	It("should allow the Canary and CanaryCommand controllers to change jobs concurrently", func() {
+		fakeClock := clocktesting.NewFakeClock(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+		cron := NewManualCron(k8sClient, k8sClient, nil, fakeClock)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				name := fmt.Sprintf("cron-concurrent-%d", i%2)
+				for k := 0; k < 100; k++ {
+					Expect(cron.Apply("default", name, "* * * * *", "old", "new")).To(Succeed())
+					cronNext(cron, "default", name)
+					if k%3 == 0 {
+						cron.Delete("default", name)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < 2; i++ {
+			cron.Delete("default", fmt.Sprintf("cron-concurrent-%d", i))
+		}
+		Expect(cron.RunDue()).To(BeZero())
+	})
+
+	DescribeTable("validates the cron schedule",
+		func(spec string, valid bool) {
+			err := validateCronSchedule(spec)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("standard schedule", "*/5 * * * *", true),
+		Entry("descriptor", "@every 1m", true),
+		Entry("empty schedule", "", false),
+		Entry("invalid schedule", "every minute", false),
+	)
+})
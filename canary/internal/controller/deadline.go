@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+const (
+	// ConditionDegraded progressDeadline이나 maxDuration을 초과한 상태를 나타내는 condition
+	ConditionDegraded = "Degraded"
+
+	// Degraded condition reason
+	ReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	ReasonMaxDurationExceeded      = "MaxDurationExceeded"
+)
+
+// checkDeadline 실행 중인 Canary가 progressDeadline이나 maxDuration을 초과하면 Degraded condition과 함께 Paused phase로 변경합니다.
+// rollbackOnDeadline이면 isCrash와 같은 rollback 경로를 사용합니다.
+// 초과하지 않았으면 가장 가까운 deadline까지 남은 시간을 반환합니다.
+func (r *CanaryReconciler) checkDeadline(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) (bool, time.Duration) {
+	if !isActive(canary) {
+		return false, 0
+	}
+
+	reason, message, remaining := deadlineExceeded(canary, oldDeploy, newDeploy, r.now())
+	if reason == "" {
+		return false, remaining
+	}
+
+	var event canaryv1alpha1.NotificationEvent
+	degraded, rolledBack := false, false
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		event, rolledBack = "", false
+		// 그 사이 Command로 멈춘 Canary는 변경하지 않습니다.
+		degraded = isActive(canary)
+		if !degraded {
+			return false
+		}
+		recordObserved(canary, oldDeploy, newDeploy)
+		meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+			Type: ConditionDegraded, Status: metav1.ConditionTrue, Reason: reason, Message: message,
+		})
+
+		if canary.Spec.RollbackOnDeadline {
+			var err error
+			if event, err = r.rollback(canary, " by deadline"); err != nil {
+				logger.Error(err, "[Reconcile] Failed to rollback Canary by deadline")
+			}
+			rolledBack = err == nil
+		} else if err := setPhase(canary, canaryv1alpha1.PhasePaused); err != nil {
+			logger.Error(err, "[Reconcile] Failed to pause Canary by deadline")
+		} else {
+			canary.Status.Message = fmt.Sprintf("[%s] Canary is degraded: %s", r.now().Format(time.RFC3339), message)
+			event = canaryv1alpha1.NotificationPaused
+		}
+		return true
+	}); err != nil {
+		logger.Error(err, "[Reconcile] Failed to update Canary status")
+		return true, 0
+	}
+	if !degraded {
+		return false, 0
+	}
+	r.Cr.Delete(canary.Namespace, canary.Name)
+	recordAnalysis(canary.Namespace, canary.Name, AnalysisResultDeadline)
+	emitAnalysis(ctx, r.Emitter, AnalysisResultDeadline, canary, oldDeploy, newDeploy)
+	if rolledBack {
+		recordRollback(canary.Namespace, canary.Name, RollbackReasonDeadline)
+	}
+	recordStatus(canary)
+	r.event(canary, corev1.EventTypeWarning, ConditionDegraded, message)
+	if event != "" {
+		r.publish(ctx, canary, event, oldDeploy, newDeploy)
+	}
+	logger.Info("[Reconcile] Canary is degraded", "namespace", canary.Namespace, "name", canary.Name, "reason", reason)
+
+	return true, 0
+}
+
+// deadlineExceeded 초과한 deadline의 reason과 message를 반환합니다.
+// 초과하지 않았으면 가장 가까운 deadline까지 남은 시간을 반환합니다.
+func deadlineExceeded(
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+	now time.Time,
+) (string, string, time.Duration) {
+	var remaining time.Duration
+	next := func(d time.Duration) {
+		if remaining == 0 || d < remaining {
+			remaining = d
+		}
+	}
+
+	if canary.Spec.MaxDuration != nil && canary.Status.StartTime != nil {
+		if maxDurationExceeded(canary, now) {
+			return ReasonMaxDurationExceeded,
+				fmt.Sprintf("canary is not complete within max duration %s", canary.Spec.MaxDuration.Duration), 0
+		}
+		next(canary.Spec.MaxDuration.Duration - now.Sub(canary.Status.StartTime.Time))
+	}
+
+	// 현재 단계가 healthy 상태가 되면 progressDeadline은 더 이상 확인하지 않습니다.
+	if canary.Spec.ProgressDeadline != nil && canary.Status.StepStartTime != nil && !isStepHealthy(canary, oldDeploy, newDeploy) {
+		elapsed := now.Sub(canary.Status.StepStartTime.Time)
+		if elapsed > canary.Spec.ProgressDeadline.Duration {
+			return ReasonProgressDeadlineExceeded,
+				fmt.Sprintf("step %d is not healthy within progress deadline %s", canary.Status.CurrentStep, canary.Spec.ProgressDeadline.Duration), 0
+		}
+		next(canary.Spec.ProgressDeadline.Duration - elapsed)
+	}
+
+	return "", "", remaining
+}
+
+// maxDurationExceeded Canary run이 시작된 후 maxDuration이 지났는지 확인합니다.
+func maxDurationExceeded(canary *canaryv1alpha1.Canary, now time.Time) bool {
+	return canary.Spec.MaxDuration != nil && canary.Status.StartTime != nil &&
+		now.Sub(canary.Status.StartTime.Time) > canary.Spec.MaxDuration.Duration
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Deadline", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var (
+		reconciler *CanaryReconciler
+		fakeClock  *clocktesting.FakeClock
+	)
+
+	// newCanary deadline의 progressDeadline, maxDuration 설정으로 2 단계를 진행 중인 Canary를 생성합니다.
+	newCanary := func(name string, deadline canaryv1alpha1.CanarySpec) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.ProgressDeadline, spec.MaxDuration = deadline.ProgressDeadline, deadline.MaxDuration
+		spec.RollbackOnDeadline = deadline.RollbackOnDeadline
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, &canaryv1alpha1.CanaryStatus{
+			State:         canaryv1alpha1.PhaseProgressing,
+			CurrentStep:   2,
+			OldReplicas:   6,
+			NewReplicas:   4,
+			StartTime:     &metav1.Time{Time: testStart},
+			StepStartTime: &metav1.Time{Time: testStart},
+		})
+	}
+
+	BeforeEach(func() {
+		reconciler, fakeClock = newTestReconciler()
+	})
+
+	It("should degrade when the step is not healthy within the progress deadline", func() {
+		canary := newCanary("deadline-progress", canaryv1alpha1.CanarySpec{ProgressDeadline: &metav1.Duration{Duration: 10 * time.Minute}})
+		unhealthy := testDeployment("new", 4, 2)
+
+		fakeClock.Step(5 * time.Minute)
+		exceeded, remaining := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), unhealthy)
+		Expect(exceeded).To(BeFalse())
+		Expect(remaining).To(Equal(5 * time.Minute))
+
+		fakeClock.Step(6 * time.Minute)
+		exceeded, _ = reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), unhealthy)
+		Expect(exceeded).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+		Expect(canary.Status.CurrentStep).To(Equal(int32(2)))
+		cond := meta.FindStatusCondition(canary.Status.Conditions, ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(ReasonProgressDeadlineExceeded))
+	})
+
+	It("should not check the progress deadline once the step is healthy", func() {
+		canary := newCanary("deadline-healthy", canaryv1alpha1.CanarySpec{ProgressDeadline: &metav1.Duration{Duration: 10 * time.Minute}})
+		fakeClock.Step(time.Hour)
+		exceeded, remaining := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 4))
+		Expect(exceeded).To(BeFalse())
+		Expect(remaining).To(BeZero())
+	})
+
+	It("should rollback when the max duration is exceeded with rollbackOnDeadline", func() {
+		canary := newCanary("deadline-max", canaryv1alpha1.CanarySpec{
+			MaxDuration:        &metav1.Duration{Duration: time.Hour},
+			RollbackOnDeadline: true,
+		})
+
+		fakeClock.Step(61 * time.Minute)
+		exceeded, _ := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 4))
+		Expect(exceeded).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseRolledBack))
+		Expect(canary.Status.CurrentStep).To(BeZero())
+		Expect(canary.Status.LastFailedStep).To(Equal(int32(2)))
+		cond := meta.FindStatusCondition(canary.Status.Conditions, ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(ReasonMaxDurationExceeded))
+	})
+
+	It("should resume a degraded canary with the apply command", func() {
+		canary := newCanary("deadline-resume", canaryv1alpha1.CanarySpec{ProgressDeadline: &metav1.Duration{Duration: 10 * time.Minute}})
+		fakeClock.Step(time.Hour)
+		exceeded, _ := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 2))
+		Expect(exceeded).To(BeTrue())
+
+		_, err := reconciler.runCommand(canary, canaryv1alpha1.CommandApply, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+		Expect(canary.Status.StartTime.Time).To(BeTemporally("==", testStart))
+		Expect(canary.Status.StepStartTime.Time).To(Equal(fakeClock.Now()))
+		Expect(meta.IsStatusConditionFalse(canary.Status.Conditions, ConditionDegraded)).To(BeTrue())
+	})
+
+	It("should not extend the max duration when resumed", func() {
+		canary := newCanary("deadline-max-resume", canaryv1alpha1.CanarySpec{MaxDuration: &metav1.Duration{Duration: time.Hour}})
+		fakeClock.Step(30 * time.Minute)
+		_, err := reconciler.runCommand(canary, canaryv1alpha1.CommandStop, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = reconciler.runCommand(canary, canaryv1alpha1.CommandApply, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canary.Status.StartTime.Time).To(BeTemporally("==", testStart))
+
+		fakeClock.Step(time.Hour)
+		exceeded, _ := reconciler.checkDeadline(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 4))
+		Expect(exceeded).To(BeTrue())
+		_, err = reconciler.runCommand(canary, canaryv1alpha1.CommandApply, nil)
+		Expect(err).To(MatchError(ContainSubstring("max duration 1h0m0s is exceeded")))
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+	})
+})
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+const (
+	// FieldManager Canary Operator가 Deployment를 변경할 때 사용하는 field manager
+	FieldManager = "canary-operator"
+
+	// ConditionDrifted Deployment가 Canary 외부에서 변경되었음을 나타내는 condition
+	ConditionDrifted = "Drifted"
+)
+
+// detectDrift 마지막 동기화 이후 Canary 외부에서 Deployment가 변경되었는지 확인하고 driftPolicy에 따라 처리합니다.
+// Deployment를 동기화하지 않아야 하면 true를 반환합니다.
+func (r *CanaryReconciler) detectDrift(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) bool {
+	policy := driftPolicy(canary)
+
+	// pause로 멈춘 Canary는 apply Command로 재개할 때까지 Deployment를 변경하지 않습니다.
+	if policy == canaryv1alpha1.DriftPause && isIdle(canary) &&
+		meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionDrifted) {
+		return true
+	}
+
+	var drifts []string
+	replicasChanged := false
+	for _, d := range []struct {
+		deploy     *appsv1.Deployment
+		replicas   int32
+		generation int64
+	}{
+		{oldDeploy, canary.Status.OldReplicas, canary.Status.OldObservedGeneration},
+		{newDeploy, canary.Status.NewReplicas, canary.Status.NewObservedGeneration},
+	} {
+		if d.generation == 0 || d.deploy.Generation == d.generation {
+			continue
+		}
+
+		actor := driftActor(d.deploy)
+		if *d.deploy.Spec.Replicas != d.replicas {
+			// HPA 모드에서는 HPA가 replicas를 변경합니다.
+			if canary.Spec.EnableHPA {
+				continue
+			}
+			replicasChanged = true
+			drifts = append(drifts, fmt.Sprintf("replicas of %s are changed from %d to %d by %s", d.deploy.Name, d.replicas, *d.deploy.Spec.Replicas, actor))
+		} else {
+			drifts = append(drifts, fmt.Sprintf("spec of %s is changed by %s", d.deploy.Name, actor))
+		}
+	}
+
+	if len(drifts) == 0 {
+		if err := patchStatus(ctx, r.Client, canary, func() bool {
+			if !meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionDrifted) {
+				return false
+			}
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionDrifted, Status: metav1.ConditionFalse, Reason: "Synced", Message: "Deployments are in sync",
+			})
+			return true
+		}); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary drift condition", "namespace", canary.Namespace, "name", canary.Name)
+		}
+		return false
+	}
+
+	message := strings.Join(drifts, ", ")
+	logger.Info("[Reconcile] Deployment drift is detected", "namespace", canary.Namespace, "name", canary.Name, "policy", policy, "drift", message)
+
+	skipSync, paused := false, false
+	reason := ""
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		skipSync, paused = false, false
+		switch {
+		case policy == canaryv1alpha1.DriftPause && canary.Status.State != canaryv1alpha1.PhaseCompleted:
+			reason = "Paused"
+			skipSync = true
+			recordObserved(canary, oldDeploy, newDeploy)
+			if isActive(canary) {
+				paused = true
+				canary.Status.State = canaryv1alpha1.PhasePaused
+				canary.Status.Message = fmt.Sprintf("[%s] Canary is stopped by drift: %s", r.now().Format(time.RFC3339), message)
+			}
+		case policy == canaryv1alpha1.DriftAdopt || replicasChanged && isObservedTotal(canary):
+			// observed dynamicTotal은 직접 변경된 replicas를 전체 replicas에 반영하므로 되돌리지 않고 adopt합니다.
+			reason = "Adopted"
+			recordObserved(canary, oldDeploy, newDeploy)
+			if replicasChanged {
+				canary.Status.TotalReplicas = *oldDeploy.Spec.Replicas + *newDeploy.Spec.Replicas
+			}
+		case replicasChanged:
+			reason = "Reverted"
+		default:
+			// revert 정책은 replicas만 되돌리므로 다른 spec 변경은 기록만 합니다.
+			reason = "Observed"
+		}
+
+		meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+			Type: ConditionDrifted, Status: metav1.ConditionTrue, Reason: reason, Message: message,
+		})
+		return true
+	}); err != nil {
+		logger.Error(err, "[Reconcile] Failed to update Canary drift condition", "namespace", canary.Namespace, "name", canary.Name)
+	}
+	r.event(canary, corev1.EventTypeWarning, ConditionDrifted, fmt.Sprintf("%s, %s", message, strings.ToLower(reason)))
+	if paused {
+		r.Cr.Delete(canary.Namespace, canary.Name)
+		r.publish(ctx, canary, canaryv1alpha1.NotificationPaused, oldDeploy, newDeploy)
+	}
+
+	return skipSync
+}
+
+// recordObserved Canary가 마지막으로 동기화한 Deployment replicas와 generation을 status에 기록합니다.
+func recordObserved(canary *canaryv1alpha1.Canary, oldDeploy, newDeploy *appsv1.Deployment) {
+	canary.Status.OldReplicas = *oldDeploy.Spec.Replicas
+	canary.Status.NewReplicas = *newDeploy.Spec.Replicas
+	canary.Status.OldObservedGeneration = oldDeploy.Generation
+	canary.Status.NewObservedGeneration = newDeploy.Generation
+}
+
+// driftPolicy Canary의 driftPolicy를 반환합니다. 기본값은 revert입니다.
+func driftPolicy(canary *canaryv1alpha1.Canary) canaryv1alpha1.DriftPolicy {
+	if canary.Spec.DriftPolicy == "" {
+		return canaryv1alpha1.DriftRevert
+	}
+
+	return canary.Spec.DriftPolicy
+}
+
+// driftActor managedFields에서 Canary Operator가 아닌 가장 최근 spec 변경자를 찾습니다.
+func driftActor(deploy *appsv1.Deployment) string {
+	actor, latest := "unknown", time.Time{}
+	for _, field := range deploy.ManagedFields {
+		if field.Manager == FieldManager || field.Subresource == "status" || field.Time == nil {
+			continue
+		}
+		if field.Time.Time.After(latest) {
+			actor, latest = field.Manager, field.Time.Time
+		}
+	}
+
+	return actor
+}
+
+// event Canary에 Kubernetes 이벤트를 기록합니다.
+func (r *CanaryReconciler) event(canary *canaryv1alpha1.Canary, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(canary, eventType, reason, message)
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Deployment drift", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var reconciler *CanaryReconciler
+	var recorder *record.FakeRecorder
+
+	// deployment manager가 마지막으로 변경한 Deployment를 반환합니다.
+	deployment := func(name string, replicas int32, generation int64, manager string) *appsv1.Deployment {
+		now := metav1.NewTime(time.Now())
+		deploy := testDeployment(name, replicas, replicas)
+		deploy.Generation = generation
+		deploy.ManagedFields = []metav1.ManagedFieldsEntry{
+			{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationUpdate, Time: &metav1.Time{Time: now.Add(-time.Minute)}},
+			{Manager: manager, Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "scale", Time: &now},
+			{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", Time: &now},
+		}
+		return deploy
+	}
+
+	newCanary := func(name string, policy canaryv1alpha1.DriftPolicy) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.DriftPolicy = policy
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, &canaryv1alpha1.CanaryStatus{
+			State:                 canaryv1alpha1.PhaseProgressing,
+			CurrentStep:           1,
+			OldReplicas:           8,
+			NewReplicas:           2,
+			OldObservedGeneration: 3,
+			NewObservedGeneration: 3,
+		})
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler, _ = newTestReconciler()
+		reconciler.Recorder = recorder
+	})
+
+	It("should ignore changes made by the canary", func() {
+		canary := newCanary("drift-none", canaryv1alpha1.DriftRevert)
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 8, 3, "kubectl"), deployment("new", 2, 3, "kubectl"))).To(BeFalse())
+		Expect(canary.Status.Conditions).To(BeEmpty())
+	})
+
+	It("should record the actor and revert", func() {
+		canary := newCanary("drift-revert", canaryv1alpha1.DriftRevert)
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 12, 4, "kubectl"), deployment("new", 2, 3, "kubectl"))).To(BeFalse())
+
+		condition := meta.FindStatusCondition(canary.Status.Conditions, ConditionDrifted)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("Reverted"))
+		Expect(condition.Message).To(Equal("replicas of old are changed from 8 to 12 by kubectl"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("by kubectl")))
+	})
+
+	It("should only record a spec change that is not reverted", func() {
+		canary := newCanary("drift-observed", canaryv1alpha1.DriftRevert)
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 8, 3, "kubectl"), deployment("new", 2, 4, "kubectl-edit"))).To(BeFalse())
+
+		condition := meta.FindStatusCondition(canary.Status.Conditions, ConditionDrifted)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("Observed"))
+		Expect(recorder.Events).To(Receive(HaveSuffix("observed")))
+	})
+
+	It("should pause the canary until it is applied again", func() {
+		canary := newCanary("drift-pause", canaryv1alpha1.DriftPause)
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 8, 3, "kubectl"), deployment("new", 2, 4, "kubectl-edit"))).To(BeTrue())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+		Expect(canary.Status.Message).To(ContainSubstring("spec of new is changed by kubectl-edit"))
+
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 8, 3, "kubectl"), deployment("new", 2, 4, "kubectl-edit"))).To(BeTrue())
+	})
+
+	It("should adopt the changed replicas as the total replicas", func() {
+		canary := newCanary("drift-adopt", canaryv1alpha1.DriftAdopt)
+		Expect(reconciler.detectDrift(ctx, logger, canary, deployment("old", 13, 4, "kubectl"), deployment("new", 2, 3, "kubectl"))).To(BeFalse())
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(15)))
+
+		oldReplicas, newReplicas := desiredReplicas(canary)
+		Expect(oldReplicas).To(Equal(int32(12)))
+		Expect(newReplicas).To(Equal(int32(3)))
+	})
+
+	It("should adopt replicas scaled out of band with the observed dynamic total instead of reverting", func() {
+		spec := testCanarySpec()
+		spec.DynamicTotal = &canaryv1alpha1.DynamicTotalSpec{Source: canaryv1alpha1.DynamicTotalObserved}
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "drift-observed-total"}, spec, &canaryv1alpha1.CanaryStatus{
+			State:                 canaryv1alpha1.PhaseProgressing,
+			CurrentStep:           1,
+			TotalReplicas:         10,
+			OldReplicas:           8,
+			NewReplicas:           2,
+			OldObservedGeneration: 3,
+			NewObservedGeneration: 3,
+		})
+
+		oldDeploy, newDeploy := deployment("old", 13, 4, "kubectl"), deployment("new", 2, 3, "kubectl")
+		Expect(reconciler.detectDrift(ctx, logger, canary, oldDeploy, newDeploy)).To(BeFalse())
+		condition := meta.FindStatusCondition(canary.Status.Conditions, ConditionDrifted)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("Adopted"))
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(15)))
+
+		// 이미 adopt한 replicas를 전체 replicas에 다시 더하지 않습니다.
+		reconciler.syncTotalReplicas(ctx, logger, canary, oldDeploy, newDeploy)
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(15)))
+		oldReplicas, newReplicas := desiredReplicas(canary)
+		Expect(oldReplicas).To(Equal(int32(12)))
+		Expect(newReplicas).To(Equal(int32(3)))
+	})
+})
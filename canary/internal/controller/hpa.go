@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// AnnotationHPAOriginal Canary가 변경하기 전 HPA의 "minReplicas,maxReplicas" 입니다.
+const AnnotationHPAOriginal = "canary.k8shuginn.io/hpa-original"
+
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch
+
+// syncHPAs HPA가 있는 Deployment의 replicas를 HPA에 맡기고 단계 비율에 따라 HPA min/max를 나눕니다.
+// Canary가 완료되거나 rollback되면 HPA를 원래 설정으로 되돌립니다.
+// 비율이 0인 Deployment는 replicas를 0으로 변경하여 HPA가 동작하지 않도록 합니다.
+func (r *CanaryReconciler) syncHPAs(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+	oldReplicas, newReplicas int32,
+) (int32, int32) {
+	oldHPA, err := r.findHPA(ctx, oldDeploy)
+	if err != nil {
+		logger.Error(err, "[Reconcile] Failed to list HorizontalPodAutoscalers", "namespace", canary.Namespace)
+		return oldReplicas, newReplicas
+	}
+	newHPA, err := r.findHPA(ctx, newDeploy)
+	if err != nil {
+		logger.Error(err, "[Reconcile] Failed to list HorizontalPodAutoscalers", "namespace", canary.Namespace)
+		return oldReplicas, newReplicas
+	}
+	if oldHPA == nil && newHPA == nil {
+		return oldReplicas, newReplicas
+	}
+
+	// old HPA의 원래 설정을 기준으로 단계 비율만큼 나눕니다.
+	base := oldHPA
+	if base == nil {
+		base = newHPA
+	}
+	baseMin, baseMax := originalHPA(base)
+	oldMin, oldMax, newMin, newMax := splitHPA(baseMin, baseMax, canary.Status.CurrentStep, maxStep(canary))
+	restore := isHPARestored(canary)
+
+	sync := func(hpa *autoscalingv2.HorizontalPodAutoscaler, deploy *appsv1.Deployment, replicas, minReplicas, maxReplicas int32) int32 {
+		if hpa == nil {
+			return replicas
+		}
+		if restore {
+			minReplicas, maxReplicas = originalHPA(hpa)
+			if err := r.restoreHPA(ctx, hpa); err != nil {
+				logger.Error(err, "[Reconcile] Failed to restore HorizontalPodAutoscaler", "namespace", hpa.Namespace, "name", hpa.Name)
+			}
+		} else if err := r.updateHPA(ctx, hpa, minReplicas, maxReplicas); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update HorizontalPodAutoscaler", "namespace", hpa.Namespace, "name", hpa.Name)
+		}
+		if replicas == 0 {
+			return 0
+		}
+
+		return clampInt32(*deploy.Spec.Replicas, minReplicas, maxReplicas)
+	}
+
+	return sync(oldHPA, oldDeploy, oldReplicas, oldMin, oldMax), sync(newHPA, newDeploy, newReplicas, newMin, newMax)
+}
+
+// restoreHPAs 두 Deployment의 HPA를 원래 설정으로 되돌립니다.
+func (r *CanaryReconciler) restoreHPAs(ctx context.Context, deploys ...*appsv1.Deployment) error {
+	for _, deploy := range deploys {
+		if deploy == nil || deploy.Name == "" {
+			continue
+		}
+		hpa, err := r.findHPA(ctx, deploy)
+		if err != nil {
+			return err
+		}
+		if hpa == nil {
+			continue
+		}
+		if err := r.restoreHPA(ctx, hpa); err != nil {
+			return fmt.Errorf("failed to restore HorizontalPodAutoscaler %s: %w", hpa.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// findHPA Deployment를 대상으로 하는 HPA를 찾습니다. 없으면 nil을 반환합니다.
+func (r *CanaryReconciler) findHPA(ctx context.Context, deploy *appsv1.Deployment) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpaList := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, hpaList, client.InNamespace(deploy.Namespace)); err != nil {
+		return nil, err
+	}
+
+	for i, hpa := range hpaList.Items {
+		if hpa.Spec.ScaleTargetRef.Kind == "Deployment" && hpa.Spec.ScaleTargetRef.Name == deploy.Name {
+			return &hpaList.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// updateHPA 원래 설정을 annotation에 기록한 뒤 HPA min/max를 변경합니다.
+func (r *CanaryReconciler) updateHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler, minReplicas, maxReplicas int32) error {
+	return patchHPA(ctx, r.Client, hpa, func() bool {
+		if _, ok := hpa.Annotations[AnnotationHPAOriginal]; !ok {
+			originalMin, originalMax := originalHPA(hpa)
+			if hpa.Annotations == nil {
+				hpa.Annotations = map[string]string{}
+			}
+			hpa.Annotations[AnnotationHPAOriginal] = fmt.Sprintf("%d,%d", originalMin, originalMax)
+		} else if hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas == minReplicas && hpa.Spec.MaxReplicas == maxReplicas {
+			return false
+		}
+
+		hpa.Spec.MinReplicas = &minReplicas
+		hpa.Spec.MaxReplicas = maxReplicas
+		return true
+	})
+}
+
+// restoreHPA annotation에 기록된 원래 설정으로 HPA를 되돌립니다.
+func (r *CanaryReconciler) restoreHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	return patchHPA(ctx, r.Client, hpa, func() bool {
+		if _, ok := hpa.Annotations[AnnotationHPAOriginal]; !ok {
+			return false
+		}
+
+		minReplicas, maxReplicas := originalHPA(hpa)
+		hpa.Spec.MinReplicas = &minReplicas
+		hpa.Spec.MaxReplicas = maxReplicas
+		delete(hpa.Annotations, AnnotationHPAOriginal)
+		return true
+	})
+}
+
+// patchHPA mutate로 변경한 HPA를 resourceVersion을 확인하는 merge patch로 저장합니다.
+// HPA controller의 status 변경과 충돌하면 최신 HPA를 다시 가져와 mutate부터 다시 적용합니다.
+func patchHPA(ctx context.Context, c client.Client, hpa *autoscalingv2.HorizontalPodAutoscaler, mutate func() bool) error {
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(hpa), hpa); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		base := hpa.DeepCopy()
+		if !mutate() {
+			return nil
+		}
+		return c.Patch(ctx, hpa, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// originalHPA Canary가 변경하기 전 HPA의 min/max를 반환합니다.
+func originalHPA(hpa *autoscalingv2.HorizontalPodAutoscaler) (int32, int32) {
+	var minReplicas, maxReplicas int32
+	if value, ok := hpa.Annotations[AnnotationHPAOriginal]; ok {
+		if _, err := fmt.Sscanf(value, "%d,%d", &minReplicas, &maxReplicas); err == nil {
+			return minReplicas, maxReplicas
+		}
+	}
+
+	minReplicas = 1
+	if hpa.Spec.MinReplicas != nil {
+		minReplicas = *hpa.Spec.MinReplicas
+	}
+	return minReplicas, hpa.Spec.MaxReplicas
+}
+
+// splitHPA 기준 min/max를 단계 비율에 따라 old, new HPA로 나눕니다.
+// HPA minReplicas는 1 이상이어야 하므로 비율이 0이 아닌 HPA는 최소 1을 갖습니다.
+func splitHPA(baseMin, baseMax, step, maxStep int32) (oldMin, oldMax, newMin, newMax int32) {
+	if maxStep <= 0 {
+		return baseMin, baseMax, baseMin, baseMax
+	}
+
+	newMin = (baseMin*step + maxStep - 1) / maxStep
+	newMax = (baseMax*step + maxStep - 1) / maxStep
+	oldMin, oldMax = baseMin-newMin, baseMax-newMax
+
+	oldMin, oldMax = maxInt32(oldMin, 1), maxInt32(oldMax, 1)
+	newMin, newMax = maxInt32(newMin, 1), maxInt32(newMax, 1)
+	return oldMin, maxInt32(oldMax, oldMin), newMin, maxInt32(newMax, newMin)
+}
+
+// isHPARestored HPA를 원래 설정으로 되돌려야 하는지 확인합니다. 완료되었거나 rollback되어 0 단계에서 멈춘 경우입니다.
+func isHPARestored(canary *canaryv1alpha1.Canary) bool {
+	return canary.Status.State == canaryv1alpha1.PhaseCompleted || (isIdle(canary) && canary.Status.CurrentStep == 0)
+}
+
+func clampInt32(value, minValue, maxValue int32) int32 {
+	return maxInt32(minValue, minInt32(value, maxValue))
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("HorizontalPodAutoscaler", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	DescribeTable("splits min/max by the step ratio",
+		func(step int32, oldMin, oldMax, newMin, newMax int32) {
+			a, b, c, d := splitHPA(4, 20, step, 5)
+			Expect([]int32{a, b, c, d}).To(Equal([]int32{oldMin, oldMax, newMin, newMax}))
+		},
+		Entry("first step", int32(0), int32(4), int32(20), int32(1), int32(1)),
+		Entry("middle step", int32(2), int32(2), int32(12), int32(2), int32(8)),
+		Entry("last step", int32(5), int32(1), int32(1), int32(4), int32(20)),
+	)
+
+	It("should split the HPA and restore it on completion", func() {
+		hpa := func(name string) *autoscalingv2.HorizontalPodAutoscaler {
+			minReplicas := int32(4)
+			return &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+				Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+					MinReplicas:    &minReplicas,
+					MaxReplicas:    20,
+				},
+			}
+		}
+		oldHPA, newHPA := hpa("hpa-old"), hpa("hpa-new")
+		Expect(k8sClient.Create(ctx, oldHPA)).To(Succeed())
+		Expect(k8sClient.Create(ctx, newHPA)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, oldHPA)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, newHPA)).To(Succeed())
+		})
+
+		reconciler, _ := newTestReconciler()
+		canary := &canaryv1alpha1.Canary{
+			Spec: canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, EnableHPA: true},
+			Status: canaryv1alpha1.CanaryStatus{
+				CurrentStep: 2,
+				State:       canaryv1alpha1.PhaseProgressing,
+			},
+		}
+
+		oldReplicas, newReplicas := reconciler.syncHPAs(ctx, logger, canary, testDeployment("hpa-old", 15, 15), testDeployment("hpa-new", 0, 0), 6, 4)
+		Expect(oldReplicas).To(Equal(int32(12)))
+		Expect(newReplicas).To(Equal(int32(2)))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(oldHPA), oldHPA)).To(Succeed())
+		Expect(*oldHPA.Spec.MinReplicas).To(Equal(int32(2)))
+		Expect(oldHPA.Spec.MaxReplicas).To(Equal(int32(12)))
+		Expect(oldHPA.Annotations).To(HaveKeyWithValue(AnnotationHPAOriginal, "4,20"))
+
+		canary.Status.CurrentStep = 5
+		canary.Status.State = canaryv1alpha1.PhaseCompleted
+		oldReplicas, newReplicas = reconciler.syncHPAs(ctx, logger, canary, testDeployment("hpa-old", 12, 12), testDeployment("hpa-new", 8, 8), 0, 10)
+		Expect(oldReplicas).To(BeZero())
+		Expect(newReplicas).To(Equal(int32(8)))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(newHPA), newHPA)).To(Succeed())
+		Expect(*newHPA.Spec.MinReplicas).To(Equal(int32(4)))
+		Expect(newHPA.Spec.MaxReplicas).To(Equal(int32(20)))
+		Expect(newHPA.Annotations).NotTo(HaveKey(AnnotationHPAOriginal))
+	})
+	It("should restore an HPA changed since it was read", func() {
+		minReplicas := int32(2)
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "hpa-stale",
+				Annotations: map[string]string{AnnotationHPAOriginal: "4,20"},
+			},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "hpa-stale"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    12,
+			},
+		}
+		Expect(k8sClient.Create(ctx, hpa)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, hpa)).To(Succeed())
+		})
+
+		// HPA controller가 그 사이 status를 변경한 것처럼 resourceVersion을 바꿉니다.
+		stale := hpa.DeepCopy()
+		hpa.Status.DesiredReplicas = 3
+		Expect(k8sClient.Status().Update(ctx, hpa)).To(Succeed())
+
+		reconciler, _ := newTestReconciler()
+		Expect(reconciler.restoreHPA(ctx, stale)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(hpa), hpa)).To(Succeed())
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(4)))
+		Expect(hpa.Spec.MaxReplicas).To(Equal(int32(20)))
+		Expect(hpa.Annotations).NotTo(HaveKey(AnnotationHPAOriginal))
+	})
+})
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+const (
+	RollbackReasonCrash    = "crash"
+	RollbackReasonCommand  = "command"
+	RollbackReasonDeadline = "deadline"
+)
+
+const (
+	AnalysisResultHealthy  = "healthy"
+	AnalysisResultCrash    = "crash"
+	AnalysisResultDeadline = "deadline"
+)
+
+// states state gauge에 노출되는 Canary phase 목록입니다.
+var states = []canaryv1alpha1.CanaryPhase{
+	canaryv1alpha1.PhasePending,
+	canaryv1alpha1.PhaseProgressing,
+	canaryv1alpha1.PhasePaused,
+	canaryv1alpha1.PhasePromoting,
+	canaryv1alpha1.PhaseCompleted,
+	canaryv1alpha1.PhaseRollingBack,
+	canaryv1alpha1.PhaseRolledBack,
+	canaryv1alpha1.PhaseFailed,
+}
+
+var (
+	currentStepGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "canary_current_step",
+			Help: "Current step of the canary",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	desiredReplicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "canary_desired_replicas",
+			Help: "Desired replicas of the old and new deployment at the current step",
+		},
+		[]string{"namespace", "name", "deployment"},
+	)
+
+	stateGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "canary_state",
+			Help: "Current state of the canary (1 for the current state, 0 otherwise)",
+		},
+		[]string{"namespace", "name", "state"},
+	)
+
+	stepDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "canary_step_duration_seconds",
+			Help:    "Time spent in a step before advancing to the next step",
+			Buckets: prometheus.ExponentialBuckets(30, 2, 12),
+		},
+		[]string{"namespace", "name"},
+	)
+
+	rollbackCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "canary_rollbacks_total",
+			Help: "Total number of canary rollbacks by reason",
+		},
+		[]string{"namespace", "name", "reason"},
+	)
+
+	analysisCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "canary_analysis_results_total",
+			Help: "Total number of new deployment health analysis results",
+		},
+		[]string{"namespace", "name", "result"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		currentStepGauge,
+		desiredReplicasGauge,
+		stateGauge,
+		stepDurationHistogram,
+		rollbackCounter,
+		analysisCounter,
+	)
+}
+
+// recordStatus Canary status를 metric에 반영합니다.
+func recordStatus(canary *canaryv1alpha1.Canary) {
+	currentStepGauge.WithLabelValues(canary.Namespace, canary.Name).Set(float64(canary.Status.CurrentStep))
+	oldReplicas, newReplicas := desiredReplicas(canary)
+	desiredReplicasGauge.WithLabelValues(canary.Namespace, canary.Name, "old").Set(float64(oldReplicas))
+	desiredReplicasGauge.WithLabelValues(canary.Namespace, canary.Name, "new").Set(float64(newReplicas))
+	for _, state := range states {
+		value := 0.0
+		if state == phase(canary) {
+			value = 1
+		}
+		stateGauge.WithLabelValues(canary.Namespace, canary.Name, string(state)).Set(value)
+	}
+}
+
+// recordStepDuration step이 유지된 시간을 기록합니다.
+func recordStepDuration(namespace, name string, duration time.Duration) {
+	stepDurationHistogram.WithLabelValues(namespace, name).Observe(duration.Seconds())
+}
+
+// recordRollback rollback 횟수를 기록합니다.
+func recordRollback(namespace, name, reason string) {
+	rollbackCounter.WithLabelValues(namespace, name, reason).Inc()
+}
+
+// recordAnalysis new deployment 상태 분석 결과를 기록합니다.
+// 단계를 진행하거나 crash, deadline으로 rollback 여부를 결정할 때만 기록하며 reconcile마다 기록하지 않습니다.
+func recordAnalysis(namespace, name, result string) {
+	analysisCounter.WithLabelValues(namespace, name, result).Inc()
+}
+
+// deleteMetrics Canary 삭제 시 해당 Canary의 metric을 제거합니다.
+func deleteMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	currentStepGauge.DeletePartialMatch(labels)
+	desiredReplicasGauge.DeletePartialMatch(labels)
+	stateGauge.DeletePartialMatch(labels)
+	stepDurationHistogram.DeletePartialMatch(labels)
+	rollbackCounter.DeletePartialMatch(labels)
+	analysisCounter.DeletePartialMatch(labels)
+}
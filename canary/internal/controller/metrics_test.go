@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Metrics", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	canary := func(name string, state canaryv1alpha1.CanaryPhase) *canaryv1alpha1.Canary {
+		return &canaryv1alpha1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2},
+			Status: canaryv1alpha1.CanaryStatus{
+				State:       state,
+				CurrentStep: 2,
+				// 아직 현재 단계로 scale되지 않은 replicas
+				OldReplicas: 8,
+				NewReplicas: 3,
+			},
+		}
+	}
+
+	It("should expose the current step, replicas and phase", func() {
+		c := canary("metrics-status", canaryv1alpha1.PhasePromoting)
+		recordStatus(c)
+
+		Expect(testutil.ToFloat64(currentStepGauge.WithLabelValues(c.Namespace, c.Name))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(desiredReplicasGauge.WithLabelValues(c.Namespace, c.Name, "old"))).To(Equal(6.0))
+		Expect(testutil.ToFloat64(desiredReplicasGauge.WithLabelValues(c.Namespace, c.Name, "new"))).To(Equal(4.0))
+		for _, state := range states {
+			expected := 0.0
+			if state == canaryv1alpha1.PhasePromoting {
+				expected = 1
+			}
+			Expect(testutil.ToFloat64(stateGauge.WithLabelValues(c.Namespace, c.Name, string(state)))).To(Equal(expected), string(state))
+		}
+	})
+
+	It("should count rollbacks by reason", func() {
+		recordRollback("default", "metrics-rollback", RollbackReasonCrash)
+		recordRollback("default", "metrics-rollback", RollbackReasonCrash)
+		recordRollback("default", "metrics-rollback", RollbackReasonCommand)
+
+		Expect(testutil.ToFloat64(rollbackCounter.WithLabelValues("default", "metrics-rollback", RollbackReasonCrash))).To(Equal(2.0))
+		Expect(testutil.ToFloat64(rollbackCounter.WithLabelValues("default", "metrics-rollback", RollbackReasonCommand))).To(Equal(1.0))
+	})
+
+	It("should observe step durations", func() {
+		recordStepDuration("default", "metrics-duration", time.Minute)
+		recordStepDuration("default", "metrics-duration", 3*time.Minute)
+
+		metric := &dto.Metric{}
+		Expect(stepDurationHistogram.WithLabelValues("default", "metrics-duration").(prometheus.Histogram).Write(metric)).To(Succeed())
+		Expect(metric.GetHistogram().GetSampleCount()).To(Equal(uint64(2)))
+		Expect(metric.GetHistogram().GetSampleSum()).To(Equal(240.0))
+	})
+
+	It("should remove the metrics of a deleted canary", func() {
+		c := canary("metrics-delete", canaryv1alpha1.PhaseProgressing)
+		recordStatus(c)
+		recordRollback(c.Namespace, c.Name, RollbackReasonCrash)
+		steps, rollbacks := testutil.CollectAndCount(currentStepGauge), testutil.CollectAndCount(rollbackCounter)
+
+		deleteMetrics(c.Namespace, c.Name)
+		Expect(testutil.CollectAndCount(currentStepGauge)).To(Equal(steps - 1))
+		Expect(testutil.CollectAndCount(rollbackCounter)).To(Equal(rollbacks - 1))
+	})
+
+	It("should record a healthy analysis only when the step advances", func() {
+		c := canary("metrics-analysis", canaryv1alpha1.PhaseProgressing)
+		c.Spec = canaryv1alpha1.CanarySpec{
+			OldDeployment: "old",
+			NewDeployment: "new",
+			TotalReplicas: 10,
+			StepReplicas:  2,
+			CronSchedule:  "* * * * *",
+		}
+		status := c.Status
+		Expect(k8sClient.Create(ctx, c)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, c)).To(Succeed())
+		})
+		c.Status = status
+		Expect(k8sClient.Status().Update(ctx, c)).To(Succeed())
+
+		healthy := analysisCounter.WithLabelValues(c.Namespace, c.Name, AnalysisResultHealthy)
+		newDeploy := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "metrics-analysis"}},
+		}}
+		newDeploy.Namespace = c.Namespace
+		reconciler := &CanaryReconciler{Client: k8sClient}
+		for i := 0; i < 3; i++ {
+			Expect(reconciler.isCrash(ctx, logger, c, &appsv1.Deployment{}, newDeploy)).To(BeFalse())
+		}
+		Expect(testutil.ToFloat64(healthy)).To(BeZero())
+
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, nil, c, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(testutil.ToFloat64(healthy)).To(Equal(1.0))
+	})
+})
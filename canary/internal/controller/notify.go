@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/notification"
+)
+
+// notify Canary에 설정된 webhook으로 lifecycle 이벤트 알림을 비동기 전송합니다.
+func (r *CanaryReconciler) notify(ctx context.Context, canary *canaryv1alpha1.Canary, event canaryv1alpha1.NotificationEvent) {
+	spec := canary.Spec.Notifications
+	if r.Notifier == nil || spec == nil || spec.SecretRef == "" || !isNotificationEnabled(spec, event) {
+		return
+	}
+
+	r.Notifier.Notify(ctx, notification.Message{
+		Event:       notification.Event(event),
+		Namespace:   canary.Namespace,
+		Name:        canary.Name,
+		Step:        canary.Status.CurrentStep,
+		OldReplicas: canary.Status.OldReplicas,
+		NewReplicas: canary.Status.NewReplicas,
+		State:       string(canary.Status.State),
+		Message:     canary.Status.Message,
+		SecretRef:   spec.SecretRef,
+		Template:    spec.Templates[event],
+	})
+}
+
+// isNotificationEnabled 알림 대상 이벤트인지 확인합니다. Events가 비어있으면 모든 이벤트를 알립니다.
+func isNotificationEnabled(spec *canaryv1alpha1.NotificationSpec, event canaryv1alpha1.NotificationEvent) bool {
+	if len(spec.Events) == 0 {
+		return true
+	}
+	for _, e := range spec.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
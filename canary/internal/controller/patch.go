@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// patchStatus mutate로 변경한 Canary status를 resourceVersion을 확인하는 merge patch로 저장합니다.
+// 다른 곳에서 Canary가 변경되어 충돌하면 최신 Canary를 다시 가져와 mutate부터 다시 적용하므로,
+// mutate는 현재 Canary만 보고 변경 내용을 결정해야 합니다. mutate가 false를 반환하면 저장하지 않습니다.
+func patchStatus(ctx context.Context, c client.Client, canary *canaryv1alpha1.Canary, mutate func() bool) error {
+	return patchCanary(ctx, c, canary, mutate, func(patch client.Patch) error {
+		return c.Status().Patch(ctx, canary, patch)
+	})
+}
+
+// patchMeta mutate로 변경한 Canary의 finalizer, annotation을 patchStatus와 같은 방식으로 저장합니다.
+func patchMeta(ctx context.Context, c client.Client, canary *canaryv1alpha1.Canary, mutate func() bool) error {
+	return patchCanary(ctx, c, canary, mutate, func(patch client.Patch) error {
+		return c.Patch(ctx, canary, patch)
+	})
+}
+
+// patchCanary 충돌하면 Canary를 다시 가져와 mutate와 patch를 반복합니다.
+func patchCanary(
+	ctx context.Context,
+	c client.Reader,
+	canary *canaryv1alpha1.Canary,
+	mutate func() bool,
+	patch func(client.Patch) error,
+) error {
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			latest := &canaryv1alpha1.Canary{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(canary), latest); err != nil {
+				return err
+			}
+			*canary = *latest
+		}
+		first = false
+
+		base := canary.DeepCopy()
+		if !mutate() {
+			return nil
+		}
+		return patch(client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+	})
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Conflict-safe patches", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	// newCanary 진행 중인 Canary와 같은 Canary의 이전 resourceVersion 사본을 반환합니다.
+	newCanary := func(name string) (*canaryv1alpha1.Canary, *canaryv1alpha1.Canary) {
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: name}, testCanarySpec(), &canaryv1alpha1.CanaryStatus{
+			State:       canaryv1alpha1.PhaseProgressing,
+			CurrentStep: 2,
+		})
+		return canary, canary.DeepCopy()
+	}
+
+	It("should apply the mutation again to the latest canary on conflict", func() {
+		canary, stale := newCanary("patch-conflict")
+		canary.Status.CurrentStep = 3
+		Expect(k8sClient.Status().Update(ctx, canary)).To(Succeed())
+
+		calls := 0
+		Expect(patchStatus(ctx, k8sClient, stale, func() bool {
+			calls++
+			stale.Status.Message = "patched"
+			return true
+		})).To(Succeed())
+		Expect(calls).To(Equal(2))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.CurrentStep).To(Equal(int32(3)))
+		Expect(canary.Status.Message).To(Equal("patched"))
+	})
+
+	It("should not write when the mutation is skipped", func() {
+		canary, _ := newCanary("patch-skip")
+		resourceVersion := canary.ResourceVersion
+		Expect(patchStatus(ctx, k8sClient, canary, func() bool { return false })).To(Succeed())
+		Expect(canary.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should not advance the step over a concurrent rollback", func() {
+		canary, stale := newCanary("patch-rollback")
+		reconciler, _ := newTestReconciler()
+		_, err := reconciler.rollback(canary, " by command")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Status().Update(ctx, canary)).To(Succeed())
+
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, nil, stale, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseRolledBack))
+		Expect(canary.Status.CurrentStep).To(BeZero())
+	})
+
+	It("should run command side effects once when the patch is retried", func() {
+		canary, _ := newCanary("patch-command-once")
+		canary.Annotations = map[string]string{Command: canaryv1alpha1.CommandRollback}
+		Expect(k8sClient.Update(ctx, canary)).To(Succeed())
+		stale := canary.DeepCopy()
+		canary.Status.CurrentStep = 3
+		Expect(k8sClient.Status().Update(ctx, canary)).To(Succeed())
+
+		reconciler, _ := newTestReconciler()
+		cron := reconciler.Cr
+		Expect(cron.Apply(canary.Namespace, canary.Name, "* * * * *", "old", "new")).To(Succeed())
+		rollbacks := rollbackCounter.WithLabelValues(canary.Namespace, canary.Name, RollbackReasonCommand)
+		before := testutil.ToFloat64(rollbacks)
+
+		ok, err := reconciler.applyCommand(ctx, logger, stale, testDeployment("old", 8, 8), testDeployment("new", 2, 2))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(testutil.ToFloat64(rollbacks) - before).To(Equal(1.0))
+		Expect(cronNext(cron, canary.Namespace, canary.Name)).To(BeNil())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseRolledBack))
+		Expect(canary.Status.LastFailedStep).To(Equal(int32(3)))
+		Expect(canary.Annotations).NotTo(HaveKey(Command))
+	})
+
+	It("should not run a command whose annotation is already removed", func() {
+		canary, _ := newCanary("patch-command-removed")
+		canary.Annotations = map[string]string{Command: canaryv1alpha1.CommandPromote}
+		Expect(k8sClient.Update(ctx, canary)).To(Succeed())
+		stale := canary.DeepCopy()
+		delete(canary.Annotations, Command)
+		Expect(k8sClient.Update(ctx, canary)).To(Succeed())
+
+		reconciler, _ := newTestReconciler()
+		ok, err := reconciler.applyCommand(ctx, logger, stale, testDeployment("old", 6, 6), testDeployment("new", 4, 4))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.CurrentStep).To(Equal(int32(2)))
+	})
+
+	It("should not overwrite the step advanced by the cron job", func() {
+		canary, stale := newCanary("patch-state-update")
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, nil, canary, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		reconciler, _ := newTestReconciler()
+		_, err = reconciler.stateUpdate(ctx, logger, stale, testDeployment("old", 4, 4), testDeployment("new", 6, 6))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.CurrentStep).To(Equal(int32(3)))
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseProgressing))
+	})
+	It("should report an invalid cron schedule instead of waiting forever", func() {
+		spec := testCanarySpec()
+		spec.CronSchedule = "every minute"
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "patch-invalid-cron"}, spec, &canaryv1alpha1.CanaryStatus{
+			State:       canaryv1alpha1.PhaseProgressing,
+			CurrentStep: 2,
+		})
+
+		reconciler, _ := newTestReconciler()
+		_, err := reconciler.stateUpdate(ctx, logger, canary, testDeployment("old", 6, 6), testDeployment("new", 4, 4))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.Message).To(ContainSubstring(`cronSchedule "every minute" is invalid`))
+		Expect(canary.Status.NextStepTime).To(BeNil())
+	})
+})
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+
+// limitByPDB 줄어드는 Deployment replicas를 매칭되는 PodDisruptionBudget의 disruptionsAllowed 만큼만 줄입니다.
+// 허용되지 않은 replicas는 pod가 available 상태가 되어 disruptionsAllowed가 늘어난 뒤 줄어듭니다.
+// spent는 같은 reconcile에서 PodDisruptionBudget별로 이미 사용한 disruption 수이며, 줄인 replicas만큼 더해집니다.
+func (r *CanaryReconciler) limitByPDB(
+	ctx context.Context,
+	logger logr.Logger,
+	deploy *appsv1.Deployment,
+	replicas int32,
+	spent map[types.UID]int32,
+) int32 {
+	current := *deploy.Spec.Replicas
+	if replicas >= current {
+		return replicas
+	}
+
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := r.List(ctx, pdbList, client.InNamespace(deploy.Namespace)); err != nil {
+		logger.Error(err, "[Reconcile] Failed to list PodDisruptionBudgets", "namespace", deploy.Namespace)
+		return replicas
+	}
+
+	var matched []types.UID
+	for _, pdb := range pdbList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(deploy.Spec.Template.Labels)) {
+			continue
+		}
+		matched = append(matched, pdb.UID)
+
+		allowed := pdb.Status.DisruptionsAllowed - spent[pdb.UID]
+		if allowed < 0 {
+			allowed = 0
+		}
+		if current-replicas > allowed {
+			replicas = current - allowed
+			logger.Info("[Reconcile] Scaling down is limited by PodDisruptionBudget", "namespace", deploy.Namespace,
+				"deployment", deploy.Name, "podDisruptionBudget", pdb.Name, "replicas", replicas)
+		}
+	}
+
+	for _, uid := range matched {
+		spent[uid] += current - replicas
+	}
+
+	return replicas
+}
+
+// syncPodDisruptionBudget Canary가 관리하는 PodDisruptionBudget을 생성 또는 수정하고, 완료되면 삭제합니다.
+func (r *CanaryReconciler) syncPodDisruptionBudget(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: canary.Namespace, Name: podDisruptionBudgetName(canary)},
+	}
+
+	if canary.Spec.PodDisruptionBudget == nil || canary.Status.State == canaryv1alpha1.PhaseCompleted {
+		// 같은 이름으로 사용자가 만든 PodDisruptionBudget은 삭제하지 않습니다.
+		if err := r.Get(ctx, client.ObjectKeyFromObject(pdb), pdb); err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "[Reconcile] Failed to get PodDisruptionBudget", "namespace", pdb.Namespace, "name", pdb.Name)
+			}
+			return
+		}
+		if !metav1.IsControlledBy(pdb, canary) {
+			return
+		}
+		if err := r.Delete(ctx, pdb); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "[Reconcile] Failed to delete PodDisruptionBudget", "namespace", pdb.Namespace, "name", pdb.Name)
+		}
+		return
+	}
+
+	matchLabels := commonLabels(oldDeploy.Spec.Template.Labels, newDeploy.Spec.Template.Labels)
+	if len(matchLabels) == 0 {
+		logger.Info("[Reconcile] Deployments have no common pod labels for PodDisruptionBudget", "namespace", canary.Namespace, "name", canary.Name)
+		return
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		minAvailable := canary.Spec.PodDisruptionBudget.MinAvailable
+		pdb.Spec.MinAvailable = &minAvailable
+		pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		return controllerutil.SetControllerReference(canary, pdb, r.Scheme)
+	})
+	if err != nil {
+		logger.Error(err, "[Reconcile] Failed to sync PodDisruptionBudget", "namespace", pdb.Namespace, "name", pdb.Name)
+	}
+}
+
+// podDisruptionBudgetName Canary가 관리하는 PodDisruptionBudget 이름입니다.
+func podDisruptionBudgetName(canary *canaryv1alpha1.Canary) string {
+	return fmt.Sprintf("%s-canary", canary.Name)
+}
+
+// commonLabels 두 label 모두에 같은 값으로 있는 label을 반환합니다.
+func commonLabels(a, b map[string]string) map[string]string {
+	common := map[string]string{}
+	for key, value := range a {
+		if b[key] == value {
+			common[key] = value
+		}
+	}
+
+	return common
+}
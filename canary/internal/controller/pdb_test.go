@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("PodDisruptionBudget", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var reconciler *CanaryReconciler
+
+	deployment := func(name, version string, replicas int32) *appsv1.Deployment {
+		deploy := testDeployment(name, replicas, replicas)
+		deploy.Spec.Template.Labels = map[string]string{"app": "pdb", "version": version}
+		return deploy
+	}
+
+	BeforeEach(func() {
+		reconciler, _ = newTestReconciler()
+	})
+
+	It("should limit scaling down to the disruptions allowed", func() {
+		minAvailable := intstr.FromInt(9)
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "existing-pdb"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "pdb"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, pdb)).To(Succeed())
+		})
+		pdb.Status.DisruptionsAllowed = 1
+		Expect(k8sClient.Status().Update(ctx, pdb)).To(Succeed())
+
+		Expect(reconciler.limitByPDB(ctx, logger, deployment("old", "v1", 10), 8, map[types.UID]int32{})).To(Equal(int32(9)))
+		Expect(reconciler.limitByPDB(ctx, logger, deployment("new", "v2", 0), 2, map[types.UID]int32{})).To(Equal(int32(2)))
+	})
+
+	It("should share the disruptions allowed between both deployments", func() {
+		minAvailable := intstr.FromInt(8)
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared-pdb"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "pdb"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, pdb)).To(Succeed())
+		})
+		pdb.Status.DisruptionsAllowed = 2
+		Expect(k8sClient.Status().Update(ctx, pdb)).To(Succeed())
+
+		spent := map[types.UID]int32{}
+		Expect(reconciler.limitByPDB(ctx, logger, deployment("old", "v1", 6), 5, spent)).To(Equal(int32(5)))
+		Expect(reconciler.limitByPDB(ctx, logger, deployment("new", "v2", 4), 1, spent)).To(Equal(int32(3)))
+		Expect(spent).To(HaveKeyWithValue(pdb.UID, int32(2)))
+	})
+
+	It("should manage a PodDisruptionBudget spanning both deployments", func() {
+		spec := testCanarySpec()
+		spec.PodDisruptionBudget = &canaryv1alpha1.PodDisruptionBudgetSpec{MinAvailable: intstr.FromString("80%")}
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "pdb-canary"}, spec, nil)
+
+		reconciler.syncPodDisruptionBudget(ctx, logger, canary, deployment("old", "v1", 10), deployment("new", "v2", 0))
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pdb-canary-canary"}, pdb)).To(Succeed())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "pdb"}))
+		Expect(pdb.Spec.MinAvailable.String()).To(Equal("80%"))
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+
+		canary.Status.State = canaryv1alpha1.PhaseCompleted
+		reconciler.syncPodDisruptionBudget(ctx, logger, canary, deployment("old", "v1", 0), deployment("new", "v2", 10))
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "pdb-canary-canary"}, pdb)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+	It("should not delete a PodDisruptionBudget the canary does not own", func() {
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "pdb-unowned"}, testCanarySpec(), nil)
+		minAvailable := intstr.FromInt(1)
+		pdb := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: podDisruptionBudgetName(canary)},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "pdb"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pdb)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, pdb)).To(Succeed())
+		})
+
+		reconciler.syncPodDisruptionBudget(ctx, logger, canary, deployment("old", "v1", 10), deployment("new", "v2", 0))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pdb), pdb)).To(Succeed())
+	})
+})
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"fmt"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// phaseTransitions phase별로 이동할 수 있는 다음 phase 목록입니다.
+// 같은 phase로의 이동은 항상 허용하며, 목록에 없는 이동은 거부합니다.
+var phaseTransitions = map[canaryv1alpha1.CanaryPhase][]canaryv1alpha1.CanaryPhase{
+	canaryv1alpha1.PhasePending: {
+		canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePromoting, canaryv1alpha1.PhaseCompleted,
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhaseProgressing: {
+		canaryv1alpha1.PhasePromoting, canaryv1alpha1.PhasePaused, canaryv1alpha1.PhaseCompleted,
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhasePromoting: {
+		canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePaused, canaryv1alpha1.PhaseCompleted,
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhasePaused: {
+		canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePromoting, canaryv1alpha1.PhaseCompleted,
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhaseCompleted: {
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhaseRollingBack: {
+		canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhaseRolledBack: {
+		canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePromoting, canaryv1alpha1.PhaseCompleted,
+		canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseFailed,
+	},
+	canaryv1alpha1.PhaseFailed: {
+		canaryv1alpha1.PhasePending, canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePromoting,
+		canaryv1alpha1.PhaseCompleted, canaryv1alpha1.PhaseRolledBack,
+	},
+}
+
+// legacyPhases 이전 버전의 문자열 state를 phase로 변환합니다.
+var legacyPhases = map[string]canaryv1alpha1.CanaryPhase{
+	"running":     canaryv1alpha1.PhaseProgressing,
+	"stop":        canaryv1alpha1.PhasePaused,
+	"complete":    canaryv1alpha1.PhaseCompleted,
+	"rollingback": canaryv1alpha1.PhaseRollingBack,
+	"error":       canaryv1alpha1.PhaseFailed,
+	"degraded":    canaryv1alpha1.PhasePaused,
+}
+
+// phase Canary의 현재 phase를 반환합니다. 아직 phase가 없으면 Pending입니다.
+func phase(canary *canaryv1alpha1.Canary) canaryv1alpha1.CanaryPhase {
+	if canary.Status.State == "" {
+		return canaryv1alpha1.PhasePending
+	}
+
+	return canary.Status.State
+}
+
+// canTransition from phase에서 to phase로 이동할 수 있는지 확인합니다.
+func canTransition(from, to canaryv1alpha1.CanaryPhase) error {
+	if from == "" {
+		from = canaryv1alpha1.PhasePending
+	}
+	if from == to {
+		return nil
+	}
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("illegal phase transition from %s to %s", from, to)
+}
+
+// setPhase 허용된 이동이면 Canary의 phase를 변경하고, 아니면 변경하지 않고 에러를 반환합니다.
+func setPhase(canary *canaryv1alpha1.Canary, to canaryv1alpha1.CanaryPhase) error {
+	if err := canTransition(canary.Status.State, to); err != nil {
+		return err
+	}
+	canary.Status.State = to
+
+	return nil
+}
+
+// isActive Canary가 단계를 진행 중인지 확인합니다.
+func isActive(canary *canaryv1alpha1.Canary) bool {
+	return canary.Status.State == canaryv1alpha1.PhaseProgressing || canary.Status.State == canaryv1alpha1.PhasePromoting
+}
+
+// isIdle Canary가 시작 전이거나 멈춘 상태인지 확인합니다. 단계 변경 Command를 허용합니다.
+func isIdle(canary *canaryv1alpha1.Canary) bool {
+	switch phase(canary) {
+	case canaryv1alpha1.PhasePending, canaryv1alpha1.PhasePaused, canaryv1alpha1.PhaseRolledBack:
+		return true
+	}
+
+	return false
+}
+
+// isNewRun apply Command가 새로운 Canary run을 시작하는지 확인합니다.
+// 시작 전이거나 롤백이 완료되어 0 단계에 있는 Canary만 새로 시작하며, 멈춘 Canary는 이전 run을 재개합니다.
+func isNewRun(canary *canaryv1alpha1.Canary) bool {
+	if canary.Status.CurrentStep != 0 {
+		return false
+	}
+
+	switch phase(canary) {
+	case canaryv1alpha1.PhasePending, canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhaseCompleted, canaryv1alpha1.PhaseFailed:
+		return true
+	}
+
+	return false
+}
+
+// recoverPhase Deployment가 없어 Failed가 된 Canary의 phase를 Deployment가 다시 생성된 후 다시 계산합니다.
+// 진행 중이던 Canary는 현재 단계부터 다시 진행하고, 0 단계의 Canary는 롤백 여부에 따라 RolledBack이나 Pending으로 변경합니다.
+func recoverPhase(canary *canaryv1alpha1.Canary) bool {
+	if canary.Status.State != canaryv1alpha1.PhaseFailed {
+		return false
+	}
+
+	switch {
+	case canary.Status.CurrentStep > 0:
+		canary.Status.State = canaryv1alpha1.PhaseProgressing
+	case canary.Status.LastFailedStep > 0:
+		canary.Status.State = canaryv1alpha1.PhaseRolledBack
+	default:
+		canary.Status.State = canaryv1alpha1.PhasePending
+	}
+	canary.Status.Message = fmt.Sprintf("Deployments are found, Canary is %s", canary.Status.State)
+
+	return true
+}
+
+// normalizePhase 이전 버전의 문자열 state를 phase로 변환합니다.
+// 0 단계에서 멈춘 Canary는 rollback 여부에 따라 RolledBack이나 Pending으로 변환합니다.
+func normalizePhase(canary *canaryv1alpha1.Canary) bool {
+	legacy, ok := legacyPhases[string(canary.Status.State)]
+	if !ok {
+		return false
+	}
+
+	if legacy == canaryv1alpha1.PhasePaused && canary.Status.CurrentStep == 0 {
+		switch {
+		case canary.Status.LastFailedStep > 0:
+			legacy = canaryv1alpha1.PhaseRolledBack
+		case canary.Status.StartTime == nil:
+			legacy = canaryv1alpha1.PhasePending
+		}
+	}
+	canary.Status.State = legacy
+
+	return true
+}
+
+// stepPhase 진행 중인 Canary의 현재 단계가 healthy 상태이면 Progressing, scale 중이면 Promoting을 반환합니다.
+func stepPhase(healthy bool) canaryv1alpha1.CanaryPhase {
+	if healthy {
+		return canaryv1alpha1.PhaseProgressing
+	}
+
+	return canaryv1alpha1.PhasePromoting
+}
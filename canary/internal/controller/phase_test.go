@@ -0,0 +1,96 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Canary phase", func() {
+	DescribeTable("transitions",
+		func(from, to canaryv1alpha1.CanaryPhase, allowed bool) {
+			err := canTransition(from, to)
+			if allowed {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring("illegal phase transition")))
+			}
+		},
+		Entry("new canary to pending", canaryv1alpha1.CanaryPhase(""), canaryv1alpha1.PhasePending, true),
+		Entry("pending to promoting", canaryv1alpha1.PhasePending, canaryv1alpha1.PhasePromoting, true),
+		Entry("promoting to progressing", canaryv1alpha1.PhasePromoting, canaryv1alpha1.PhaseProgressing, true),
+		Entry("progressing to paused", canaryv1alpha1.PhaseProgressing, canaryv1alpha1.PhasePaused, true),
+		Entry("paused to completed", canaryv1alpha1.PhasePaused, canaryv1alpha1.PhaseCompleted, true),
+		Entry("completed to rolled back", canaryv1alpha1.PhaseCompleted, canaryv1alpha1.PhaseRolledBack, true),
+		Entry("rolling back to rolled back", canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseRolledBack, true),
+		Entry("rolled back to promoting", canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhasePromoting, true),
+		Entry("failed to pending", canaryv1alpha1.PhaseFailed, canaryv1alpha1.PhasePending, true),
+		Entry("failed to progressing", canaryv1alpha1.PhaseFailed, canaryv1alpha1.PhaseProgressing, true),
+		Entry("same phase", canaryv1alpha1.PhaseFailed, canaryv1alpha1.PhaseFailed, true),
+		Entry("pending to paused", canaryv1alpha1.PhasePending, canaryv1alpha1.PhasePaused, false),
+		Entry("completed to progressing", canaryv1alpha1.PhaseCompleted, canaryv1alpha1.PhaseProgressing, false),
+		Entry("rolling back to promoting", canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhasePromoting, false),
+		Entry("rolling back to completed", canaryv1alpha1.PhaseRollingBack, canaryv1alpha1.PhaseCompleted, false),
+		Entry("rolled back to paused", canaryv1alpha1.PhaseRolledBack, canaryv1alpha1.PhasePaused, false),
+		Entry("failed to paused", canaryv1alpha1.PhaseFailed, canaryv1alpha1.PhasePaused, false),
+	)
+
+	DescribeTable("legacy states",
+		func(state string, step, lastFailedStep int32, started bool, expected canaryv1alpha1.CanaryPhase) {
+			canary := &canaryv1alpha1.Canary{Status: canaryv1alpha1.CanaryStatus{
+				State:          canaryv1alpha1.CanaryPhase(state),
+				CurrentStep:    step,
+				LastFailedStep: lastFailedStep,
+			}}
+			if started {
+				canary.Status.StartTime = &metav1.Time{}
+			}
+			Expect(normalizePhase(canary)).To(BeTrue())
+			Expect(canary.Status.State).To(Equal(expected))
+		},
+		Entry("running", "running", int32(2), int32(0), true, canaryv1alpha1.PhaseProgressing),
+		Entry("stop before start", "stop", int32(0), int32(0), false, canaryv1alpha1.PhasePending),
+		Entry("stop after rollback", "stop", int32(0), int32(3), true, canaryv1alpha1.PhaseRolledBack),
+		Entry("stop by command", "stop", int32(2), int32(0), true, canaryv1alpha1.PhasePaused),
+		Entry("complete", "complete", int32(5), int32(0), true, canaryv1alpha1.PhaseCompleted),
+		Entry("rollingback", "rollingback", int32(2), int32(2), true, canaryv1alpha1.PhaseRollingBack),
+		Entry("error", "error", int32(0), int32(0), false, canaryv1alpha1.PhaseFailed),
+		Entry("degraded", "degraded", int32(2), int32(0), true, canaryv1alpha1.PhasePaused),
+	)
+
+	DescribeTable("recovery from Failed",
+		func(step, lastFailedStep int32, expected canaryv1alpha1.CanaryPhase) {
+			canary := &canaryv1alpha1.Canary{Status: canaryv1alpha1.CanaryStatus{
+				State:          canaryv1alpha1.PhaseFailed,
+				CurrentStep:    step,
+				LastFailedStep: lastFailedStep,
+			}}
+			Expect(recoverPhase(canary)).To(BeTrue())
+			Expect(canary.Status.State).To(Equal(expected))
+		},
+		Entry("before start", int32(0), int32(0), canaryv1alpha1.PhasePending),
+		Entry("while running", int32(2), int32(0), canaryv1alpha1.PhaseProgressing),
+		Entry("after rollback", int32(0), int32(3), canaryv1alpha1.PhaseRolledBack),
+	)
+
+	It("should keep a typed phase", func() {
+		canary := &canaryv1alpha1.Canary{Status: canaryv1alpha1.CanaryStatus{State: canaryv1alpha1.PhasePaused}}
+		Expect(normalizePhase(canary)).To(BeFalse())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+	})
+
+	It("should reject a command with an illegal transition", func() {
+		reconciler, _ := newTestReconciler()
+		canary := &canaryv1alpha1.Canary{Spec: canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2}}
+
+		for _, cmd := range []string{canaryv1alpha1.CommandApply, canaryv1alpha1.CommandCompletion} {
+			canary.Status = canaryv1alpha1.CanaryStatus{State: canaryv1alpha1.PhaseRollingBack, CurrentStep: 2}
+			_, err := reconciler.runCommand(canary, cmd, nil)
+			Expect(err).To(MatchError(ContainSubstring("illegal phase transition from RollingBack")))
+			Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseRollingBack))
+			Expect(canary.Status.CurrentStep).To(Equal(int32(2)))
+		}
+	})
+})
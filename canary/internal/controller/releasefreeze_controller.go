@@ -0,0 +1,327 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+const (
+	// StepBlocked condition reason
+	ReasonReleaseFreeze = "ReleaseFreeze"
+)
+
+// ReleaseFreezeReconciler reconciles a ReleaseFreeze object
+type ReleaseFreezeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder 동결되거나 재개된 Canary에 Kubernetes Event를 기록합니다.
+	Recorder record.EventRecorder
+
+	// Clock 현재 시간을 제공합니다. 비어 있으면 실제 시간을 사용합니다.
+	Clock clock.PassiveClock
+}
+
+//+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=releasefreezes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=canary.k8shuginn.io,resources=releasefreezes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile 모든 ReleaseFreeze에 대해 Canary의 동결 상태를 다시 계산합니다.
+// 단계 진행은 advanceStep에서 막으며, 여기서는 동결되거나 재개된 Canary의 condition과 Event를 기록합니다.
+func (r *ReleaseFreezeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	freezes := &canaryv1alpha1.ReleaseFreezeList{}
+	if err := r.List(ctx, freezes); err != nil {
+		return ctrl.Result{}, err
+	}
+	canaries := &canaryv1alpha1.CanaryList{}
+	if err := r.List(ctx, canaries); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := r.now()
+	// 한 Canary의 오류로 다른 Canary의 동결 상태가 갱신되지 않는 일이 없도록 오류를 기록하고 계속 진행한 뒤 다시 reconcile합니다.
+	var failed error
+	frozen := make(map[string][]string)
+	for i := range canaries.Items {
+		canary := &canaries.Items[i]
+		freeze, err := matchFreeze(ctx, r.Client, canary, freezes.Items, now)
+		if err != nil {
+			logger.Error(err, "[ReleaseFreeze] Failed to match release freezes", "namespace", canary.Namespace, "name", canary.Name)
+			failed = err
+			continue
+		}
+		if freeze != nil && isActive(canary) {
+			frozen[freeze.Name] = append(frozen[freeze.Name], canary.Namespace+"/"+canary.Name)
+		}
+		if err := r.markFrozen(ctx, canary, freeze); err != nil {
+			logger.Error(err, "[ReleaseFreeze] Failed to update Canary status", "namespace", canary.Namespace, "name", canary.Name)
+			failed = err
+		}
+	}
+
+	// 만료되면 동결된 Canary를 재개하도록 가장 빠른 만료 시간에 다시 reconcile합니다.
+	var requeueAfter time.Duration
+	for i := range freezes.Items {
+		freeze := &freezes.Items[i]
+		active := isFreezeActive(freeze, now)
+		if active && freeze.Spec.ExpiresAt != nil {
+			if wait := freeze.Spec.ExpiresAt.Sub(now); requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+		}
+
+		names := frozen[freeze.Name]
+		sort.Strings(names)
+		if freeze.Status.Active == active && equalStrings(freeze.Status.FrozenCanaries, names) {
+			continue
+		}
+		freeze.Status.Active = active
+		freeze.Status.FrozenCanaries = names
+		if err := r.Status().Update(ctx, freeze); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("[ReleaseFreeze] Status is updated", "name", freeze.Name, "active", active, "canaries", len(names))
+	}
+
+	if failed != nil {
+		return ctrl.Result{}, failed
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// markFrozen 동결 여부를 Canary의 StepBlocked condition에 기록하고, 변경되면 Event를 전송합니다.
+// 실행 중인 Canary만 동결하며, freeze가 해제되면 ReleaseFreeze로 막힌 condition만 해제합니다.
+func (r *ReleaseFreezeReconciler) markFrozen(ctx context.Context, canary *canaryv1alpha1.Canary, freeze *canaryv1alpha1.ReleaseFreeze) error {
+	var eventType, reason, message string
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		eventType = ""
+		cond := meta.FindStatusCondition(canary.Status.Conditions, ConditionStepBlocked)
+		isFrozen := cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == ReasonReleaseFreeze
+
+		if freeze != nil && isActive(canary) {
+			message = freezeMessage(freeze)
+			if isFrozen && cond.Message == message {
+				return false
+			}
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionStepBlocked, Status: metav1.ConditionTrue, Reason: ReasonReleaseFreeze, Message: message,
+			})
+			eventType, reason = corev1.EventTypeWarning, "Frozen"
+			return true
+		}
+
+		if !isFrozen {
+			return false
+		}
+		meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+			Type: ConditionStepBlocked, Status: metav1.ConditionFalse, Reason: "Unfrozen", Message: "Release freeze is lifted",
+		})
+		eventType, reason, message = corev1.EventTypeNormal, "Unfrozen", "Release freeze is lifted, step advancement is resumed"
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if eventType != "" {
+		r.event(canary, eventType, reason, message)
+	}
+	return nil
+}
+
+// now 현재 시간을 반환합니다. Clock이 설정되어 있으면 Clock의 시간을 사용합니다.
+func (r *ReleaseFreezeReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+
+	return r.Clock.Now()
+}
+
+// event Recorder가 설정되어 있으면 Canary에 Kubernetes Event를 기록합니다.
+func (r *ReleaseFreezeReconciler) event(canary *canaryv1alpha1.Canary, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(canary, eventType, reason, message)
+}
+
+// freezeBlocked Canary에 적용되는 ReleaseFreeze가 있으면 condition reason과 message를 반환합니다.
+func freezeBlocked(ctx context.Context, c client.Reader, canary *canaryv1alpha1.Canary, now time.Time) (string, string, error) {
+	freezes := &canaryv1alpha1.ReleaseFreezeList{}
+	if err := c.List(ctx, freezes); err != nil {
+		return "", "", err
+	}
+
+	freeze, err := matchFreeze(ctx, c, canary, freezes.Items, now)
+	if err != nil || freeze == nil {
+		return "", "", err
+	}
+
+	return ReasonReleaseFreeze, freezeMessage(freeze), nil
+}
+
+// matchFreeze Canary에 적용되는 첫 번째 활성 ReleaseFreeze를 이름 순서로 찾습니다.
+func matchFreeze(
+	ctx context.Context,
+	c client.Reader,
+	canary *canaryv1alpha1.Canary,
+	freezes []canaryv1alpha1.ReleaseFreeze,
+	now time.Time,
+) (*canaryv1alpha1.ReleaseFreeze, error) {
+	sort.Slice(freezes, func(i, j int) bool { return freezes[i].Name < freezes[j].Name })
+
+	var namespace *corev1.Namespace
+	for i := range freezes {
+		freeze := &freezes[i]
+		if !isFreezeActive(freeze, now) {
+			continue
+		}
+
+		if freeze.Spec.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(freeze.Spec.Selector)
+			if err != nil {
+				return nil, err
+			}
+			if !selector.Matches(labels.Set(canary.Labels)) {
+				continue
+			}
+		}
+
+		if freeze.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(freeze.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, err
+			}
+			if namespace == nil {
+				namespace = &corev1.Namespace{}
+				if err := c.Get(ctx, client.ObjectKey{Name: canary.Namespace}, namespace); err != nil {
+					return nil, err
+				}
+			}
+			if !selector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+
+		return freeze, nil
+	}
+
+	return nil, nil
+}
+
+// isFreezeActive 삭제 중이거나 만료되지 않은 ReleaseFreeze인지 확인합니다.
+func isFreezeActive(freeze *canaryv1alpha1.ReleaseFreeze, now time.Time) bool {
+	if freeze.DeletionTimestamp != nil {
+		return false
+	}
+
+	return freeze.Spec.ExpiresAt == nil || now.Before(freeze.Spec.ExpiresAt.Time)
+}
+
+// freezeMessage StepBlocked condition과 Event에 기록할 message를 만듭니다.
+func freezeMessage(freeze *canaryv1alpha1.ReleaseFreeze) string {
+	message := fmt.Sprintf("Step advancement is paused by release freeze %q", freeze.Name)
+	if freeze.Spec.Reason != "" {
+		message += ": " + freeze.Spec.Reason
+	}
+
+	return message
+}
+
+// equalStrings 두 문자열 목록이 같은지 확인합니다.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// freezeRequests Canary나 Namespace가 변경되면 동결 상태를 다시 계산하도록 모든 ReleaseFreeze를 reconcile합니다.
+func (r *ReleaseFreezeReconciler) freezeRequests(ctx context.Context, _ client.Object) []reconcile.Request {
+	freezes := &canaryv1alpha1.ReleaseFreezeList{}
+	if err := r.List(ctx, freezes); err != nil {
+		log.FromContext(ctx).Error(err, "[ReleaseFreeze] Failed to list release freezes")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(freezes.Items))
+	for i := range freezes.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&freezes.Items[i])})
+	}
+
+	return requests
+}
+
+// canaryFreezePredicate 동결 여부에 영향을 주는 Canary label과 state 변경만 통과시킵니다.
+var canaryFreezePredicate = predicate.Or(
+	predicate.LabelChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCanary, ok := e.ObjectOld.(*canaryv1alpha1.Canary)
+			if !ok {
+				return false
+			}
+			newCanary, ok := e.ObjectNew.(*canaryv1alpha1.Canary)
+			if !ok {
+				return false
+			}
+			return oldCanary.Status.State != newCanary.Status.State
+		},
+	},
+)
+
+// SetupWithManager sets up the controller with the Manager.
+// 새로 생성되거나 label, state가 바뀐 Canary와 label이 바뀐 Namespace도 바로 동결 상태를 다시 계산합니다.
+func (r *ReleaseFreezeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&canaryv1alpha1.ReleaseFreeze{}).
+		Watches(&canaryv1alpha1.Canary{}, handler.EnqueueRequestsFromMapFunc(r.freezeRequests),
+			builder.WithPredicates(canaryFreezePredicate)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.freezeRequests),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Complete(r)
+}
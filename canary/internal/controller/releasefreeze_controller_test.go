@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("ReleaseFreeze Controller", func() {
+	ctx := context.Background()
+	var (
+		reconciler *ReleaseFreezeReconciler
+		recorder   *record.FakeRecorder
+	)
+
+	newCanary := func(name string, labels map[string]string) *canaryv1alpha1.Canary {
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name, Labels: labels}, testCanarySpec(),
+			&canaryv1alpha1.CanaryStatus{State: canaryv1alpha1.PhaseProgressing})
+	}
+
+	newFreeze := func(name string, spec canaryv1alpha1.ReleaseFreezeSpec) *canaryv1alpha1.ReleaseFreeze {
+		freeze := &canaryv1alpha1.ReleaseFreeze{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+		Expect(k8sClient.Create(ctx, freeze)).To(Succeed())
+		return freeze
+	}
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ReleaseFreezeReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+	})
+
+	It("should freeze the matching canaries and resume them when lifted", func() {
+		frozen := newCanary("freeze-match", map[string]string{"tier": "backend"})
+		other := newCanary("freeze-other", map[string]string{"tier": "frontend"})
+		freeze := newFreeze("backend-freeze", canaryv1alpha1.ReleaseFreezeSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+			Reason:   "incident",
+		})
+
+		reconcile()
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(frozen), frozen)).To(Succeed())
+		cond := meta.FindStatusCondition(frozen.Status.Conditions, ConditionStepBlocked)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(ReasonReleaseFreeze))
+		Expect(cond.Message).To(ContainSubstring("incident"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Frozen")))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(other), other)).To(Succeed())
+		Expect(meta.FindStatusCondition(other.Status.Conditions, ConditionStepBlocked)).To(BeNil())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(freeze), freeze)).To(Succeed())
+		Expect(freeze.Status.Active).To(BeTrue())
+		Expect(freeze.Status.FrozenCanaries).To(Equal([]string{"default/freeze-match"}))
+
+		// step 진행도 막힙니다.
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, nil, frozen, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		Expect(k8sClient.Delete(ctx, freeze)).To(Succeed())
+		reconcile()
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(frozen), frozen)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(frozen.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring("Unfrozen")))
+
+		ok, err = advanceStep(ctx, k8sClient, k8sClient, nil, frozen, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should not freeze canaries after the freeze expires", func() {
+		canary := newCanary("freeze-expired", nil)
+		expired := metav1.NewTime(time.Now().Add(-time.Minute))
+		freeze := newFreeze("expired-freeze", canaryv1alpha1.ReleaseFreezeSpec{ExpiresAt: &expired})
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, freeze)).To(Succeed())
+		})
+
+		reconcile()
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(meta.FindStatusCondition(canary.Status.Conditions, ConditionStepBlocked)).To(BeNil())
+	})
+
+	It("should freeze canaries in the matching namespaces", func() {
+		canary := newCanary("freeze-namespace", nil)
+
+		freeze := &canaryv1alpha1.ReleaseFreeze{Spec: canaryv1alpha1.ReleaseFreezeSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "default"}},
+		}}
+		matched, err := matchFreeze(ctx, k8sClient, canary, []canaryv1alpha1.ReleaseFreeze{*freeze}, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matched).NotTo(BeNil())
+
+		freeze.Spec.NamespaceSelector.MatchLabels = map[string]string{"env": "production"}
+		matched, err = matchFreeze(ctx, k8sClient, canary, []canaryv1alpha1.ReleaseFreeze{*freeze}, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matched).To(BeNil())
+	})
+
+	It("should expire the freeze with the reconciler clock", func() {
+		fakeClock := clocktesting.NewFakeClock(testStart)
+		reconciler.Clock = fakeClock
+		canary := newCanary("freeze-clock", map[string]string{"tier": "clock"})
+		expiresAt := metav1.NewTime(testStart.Add(time.Hour))
+		freeze := newFreeze("clock-freeze", canaryv1alpha1.ReleaseFreezeSpec{
+			Selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "clock"}},
+			ExpiresAt: &expiresAt,
+		})
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, freeze)).To(Succeed())
+		})
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+
+		fakeClock.Step(2 * time.Hour)
+		reconcile()
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(canary.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+	})
+
+	It("should keep freezing the other canaries when a canary fails to match", func() {
+		broken := newCanary("freeze-broken", map[string]string{"tier": "broken"})
+		frozen := newCanary("freeze-continue", map[string]string{"tier": "continue"})
+		invalid := newFreeze("a-invalid-freeze", canaryv1alpha1.ReleaseFreezeSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "broken"}},
+			NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "env", Operator: "Invalid"},
+			}},
+		})
+		valid := newFreeze("b-valid-freeze", canaryv1alpha1.ReleaseFreezeSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "continue"}},
+		})
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, invalid)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, valid)).To(Succeed())
+		})
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(frozen), frozen)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(frozen.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(broken), broken)).To(Succeed())
+		Expect(meta.FindStatusCondition(broken.Status.Conditions, ConditionStepBlocked)).To(BeNil())
+
+		Expect(reconciler.freezeRequests(ctx, frozen)).To(ContainElements(
+			ctrl.Request{NamespacedName: client.ObjectKeyFromObject(invalid)},
+			ctrl.Request{NamespacedName: client.ObjectKeyFromObject(valid)},
+		))
+	})
+
+	It("should only watch the canary changes affecting the freeze", func() {
+		oldCanary := &canaryv1alpha1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "backend"}},
+			Status:     canaryv1alpha1.CanaryStatus{State: canaryv1alpha1.PhaseProgressing},
+		}
+		updated := func(mutate func(*canaryv1alpha1.Canary)) bool {
+			newCanary := oldCanary.DeepCopy()
+			mutate(newCanary)
+			return canaryFreezePredicate.Update(event.UpdateEvent{ObjectOld: oldCanary, ObjectNew: newCanary})
+		}
+
+		Expect(updated(func(c *canaryv1alpha1.Canary) { c.Labels["tier"] = "frontend" })).To(BeTrue())
+		Expect(updated(func(c *canaryv1alpha1.Canary) { c.Status.State = canaryv1alpha1.PhasePaused })).To(BeTrue())
+		Expect(updated(func(c *canaryv1alpha1.Canary) { c.Status.CurrentStep = 2 })).To(BeFalse())
+	})
+})
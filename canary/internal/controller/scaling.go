@@ -0,0 +1,99 @@
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// desiredReplicas 현재 단계의 old, new Deployment replicas를 반환합니다.
+// dynamicTotal이면 replicas 수 대신 단계 비율을 유지합니다.
+// totalReplicas가 stepReplicas보다 작아 진행할 단계가 없으면 모든 replicas를 old Deployment에 유지합니다.
+func desiredReplicas(canary *canaryv1alpha1.Canary) (int32, int32) {
+	if isDynamicTotal(canary) {
+		total, lastStep := totalReplicas(canary), maxStep(canary)
+		if lastStep == 0 {
+			return total, 0
+		}
+		newReplicas := total
+		if canary.Status.CurrentStep < lastStep {
+			newReplicas = (total*canary.Status.CurrentStep + lastStep - 1) / lastStep
+		}
+		return total - newReplicas, newReplicas
+	}
+
+	newReplicas := canary.Spec.StepReplicas * canary.Status.CurrentStep
+	return canary.Spec.TotalReplicas - newReplicas, newReplicas
+}
+
+// surgeReplicas surge-then-shrink 정책에 따라 이번 reconcile에서 적용할 replicas를 계산합니다.
+// 늘어나는 Deployment는 maxSurge 안에서 먼저 늘리고, 줄어드는 Deployment는 두 Deployment의
+// available replicas 합이 totalReplicas - maxUnavailable 이상으로 유지되는 만큼만 줄입니다.
+// 줄이지 못한 replicas는 Deployment status가 변경되어 다시 reconcile될 때 줄어듭니다.
+func surgeReplicas(
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+	desiredOld, desiredNew int32,
+) (int32, int32) {
+	total := int(totalReplicas(canary))
+	maxSurge := int(canary.Spec.StepReplicas)
+	if canary.Spec.Scaling.MaxSurge != nil {
+		if value, err := intstr.GetScaledValueFromIntOrPercent(canary.Spec.Scaling.MaxSurge, total, true); err == nil {
+			maxSurge = value
+		}
+	}
+	maxUnavailable := 0
+	if canary.Spec.Scaling.MaxUnavailable != nil {
+		if value, err := intstr.GetScaledValueFromIntOrPercent(canary.Spec.Scaling.MaxUnavailable, total, false); err == nil {
+			maxUnavailable = value
+		}
+	}
+	// 둘 다 0이면 진행할 수 없으므로 Deployment와 같이 maxSurge를 1로 사용합니다.
+	if maxSurge == 0 && maxUnavailable == 0 {
+		maxSurge = 1
+	}
+	maxTotal := int32(total + maxSurge)
+	minAvailable := int32(total - maxUnavailable)
+
+	oldReplicas, newReplicas := *oldDeploy.Spec.Replicas, *newDeploy.Spec.Replicas
+
+	surge := func() {
+		if desiredNew > newReplicas {
+			newReplicas = minInt32(desiredNew, maxInt32(newReplicas, maxTotal-oldReplicas))
+		}
+		if desiredOld > oldReplicas {
+			oldReplicas = minInt32(desiredOld, maxInt32(oldReplicas, maxTotal-newReplicas))
+		}
+	}
+
+	// 늘어나는 Deployment를 먼저 늘립니다.
+	surge()
+
+	// 줄어드는 Deployment는 상대 Deployment의 available replicas만큼만 줄입니다.
+	if desiredOld < oldReplicas {
+		oldReplicas = maxInt32(desiredOld, minInt32(oldReplicas, minAvailable-newDeploy.Status.AvailableReplicas))
+	}
+	if desiredNew < newReplicas {
+		newReplicas = maxInt32(desiredNew, minInt32(newReplicas, minAvailable-oldDeploy.Status.AvailableReplicas))
+	}
+
+	// maxSurge가 부족해 늘리지 못했다면 줄어든 만큼 다시 늘립니다.
+	surge()
+
+	return oldReplicas, newReplicas
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
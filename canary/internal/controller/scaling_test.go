@@ -0,0 +1,64 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Surge-then-shrink scaling", func() {
+	canary := func(step int32, scaling canaryv1alpha1.ScalingSpec) *canaryv1alpha1.Canary {
+		return &canaryv1alpha1.Canary{
+			Spec: canaryv1alpha1.CanarySpec{
+				TotalReplicas: 10,
+				StepReplicas:  2,
+				Scaling:       &scaling,
+			},
+			Status: canaryv1alpha1.CanaryStatus{CurrentStep: step},
+		}
+	}
+
+	percent := intstr.FromString("20%")
+	zero := intstr.FromInt(0)
+	one := intstr.FromInt(1)
+
+	DescribeTable("calculates the replicas of each reconcile",
+		func(c *canaryv1alpha1.Canary, oldDeploy, newDeploy *appsv1.Deployment, expectedOld, expectedNew int32) {
+			desiredOld, desiredNew := desiredReplicas(c)
+			oldReplicas, newReplicas := surgeReplicas(c, oldDeploy, newDeploy, desiredOld, desiredNew)
+			Expect(oldReplicas).To(Equal(expectedOld))
+			Expect(newReplicas).To(Equal(expectedNew))
+		},
+		Entry("scales the new deployment up first",
+			canary(1, canaryv1alpha1.ScalingSpec{}), testDeployment("old", 10, 10), testDeployment("new", 0, 0), int32(10), int32(2)),
+		Entry("keeps the old deployment until the new pods are available",
+			canary(1, canaryv1alpha1.ScalingSpec{}), testDeployment("old", 10, 10), testDeployment("new", 2, 1), int32(9), int32(2)),
+		Entry("scales the old deployment down when the new pods are available",
+			canary(1, canaryv1alpha1.ScalingSpec{}), testDeployment("old", 10, 10), testDeployment("new", 2, 2), int32(8), int32(2)),
+		Entry("limits the surge by percentage",
+			canary(2, canaryv1alpha1.ScalingSpec{MaxSurge: &percent}), testDeployment("old", 8, 8), testDeployment("new", 2, 2), int32(8), int32(4)),
+		Entry("scales down first without surge",
+			canary(1, canaryv1alpha1.ScalingSpec{MaxSurge: &zero, MaxUnavailable: &one}), testDeployment("old", 10, 10), testDeployment("new", 0, 0), int32(9), int32(1)),
+		Entry("scales the old deployment up first when going back",
+			canary(0, canaryv1alpha1.ScalingSpec{}), testDeployment("old", 8, 8), testDeployment("new", 2, 2), int32(10), int32(2)),
+		Entry("scales the new deployment down when the old pods are available",
+			canary(0, canaryv1alpha1.ScalingSpec{}), testDeployment("old", 10, 10), testDeployment("new", 2, 2), int32(10), int32(0)),
+	)
+
+	DescribeTable("completes the canary",
+		func(oldDeploy, newDeploy *appsv1.Deployment, expected bool) {
+			c := canary(5, canaryv1alpha1.ScalingSpec{})
+			recordObserved(c, oldDeploy, newDeploy)
+			Expect(isRolledOut(c, newDeploy)).To(Equal(expected))
+		},
+		Entry("not while the old deployment is shrinking after the surge",
+			testDeployment("old", 2, 2), testDeployment("new", 10, 10), false),
+		Entry("not while the new pods are unavailable",
+			testDeployment("old", 0, 0), testDeployment("new", 10, 8), false),
+		Entry("when the old deployment is scaled down and the new pods are available",
+			testDeployment("old", 0, 0), testDeployment("new", 10, 10), true),
+	)
+})
@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// simulation fake clock과 manual Cron으로 Canary reconcile을 실제 시간과 관계없이 실행하는 test harness입니다.
+// envtest에는 Deployment controller가 없으므로 reconcile할 때마다 Deployment를 available 상태로 갱신합니다.
+type simulation struct {
+	ctx        context.Context
+	clock      *clocktesting.FakeClock
+	cron       *Cron
+	reconciler *CanaryReconciler
+	key        client.ObjectKey
+	deploys    []string
+}
+
+// newSimulation old, new Deployment와 Canary를 생성하고 Pending 상태까지 reconcile합니다.
+func newSimulation(name string, spec canaryv1alpha1.CanarySpec) *simulation {
+	s := newCanarySimulation(name, spec, func(s *simulation) {
+		s.createDeployments(spec.TotalReplicas)
+	})
+	s.reconcile()
+	return s
+}
+
+// newCanarySimulation fake clock과 manual Cron을 사용하는 simulation을 만들고 Canary를 생성합니다.
+// beforeCanary는 Canary를 생성하기 전에 실행됩니다.
+func newCanarySimulation(name string, spec canaryv1alpha1.CanarySpec, beforeCanary func(s *simulation)) *simulation {
+	reconciler, fakeClock := newTestReconciler()
+	s := &simulation{
+		ctx:        context.Background(),
+		clock:      fakeClock,
+		cron:       reconciler.Cr,
+		reconciler: reconciler,
+		key:        client.ObjectKey{Namespace: "default", Name: name},
+		deploys:    []string{name + "-old", name + "-new"},
+	}
+
+	spec.OldDeployment, spec.NewDeployment = s.deploys[0], s.deploys[1]
+	if beforeCanary != nil {
+		beforeCanary(s)
+	}
+	canary := &canaryv1alpha1.Canary{ObjectMeta: metav1.ObjectMeta{Namespace: s.key.Namespace, Name: name}, Spec: spec}
+	Expect(k8sClient.Create(s.ctx, canary)).To(Succeed())
+	DeferCleanup(s.delete)
+
+	return s
+}
+
+// createDeployments 모든 replicas가 old Deployment에 있는 old, new Deployment를 생성합니다.
+func (s *simulation) createDeployments(totalReplicas int32) {
+	s.createDeployment(s.deploys[0], totalReplicas)
+	s.createDeployment(s.deploys[1], 0)
+}
+
+// createDeployment track label로 구분되는 Deployment를 생성합니다.
+func (s *simulation) createDeployment(name string, replicas int32) {
+	labels := map[string]string{"app": s.key.Name, "track": name}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: s.key.Namespace, Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: name}}},
+			},
+		},
+	}
+	Expect(k8sClient.Create(s.ctx, deploy)).To(Succeed())
+}
+
+// reconcile Canary가 더 이상 변경되지 않을 때까지 Reconcile을 반복합니다.
+func (s *simulation) reconcile() {
+	for i := 0; i < 10; i++ {
+		before := s.canary().ResourceVersion
+		_, err := s.reconciler.Reconcile(s.ctx, ctrl.Request{NamespacedName: s.key})
+		Expect(err).NotTo(HaveOccurred())
+		s.markAvailable()
+		if s.canary().ResourceVersion == before {
+			return
+		}
+	}
+}
+
+// markAvailable Deployment controller 대신 모든 replicas를 available 상태로 갱신합니다.
+func (s *simulation) markAvailable() {
+	for _, name := range s.deploys {
+		deploy := &appsv1.Deployment{}
+		if err := k8sClient.Get(s.ctx, client.ObjectKey{Namespace: s.key.Namespace, Name: name}, deploy); apierrors.IsNotFound(err) {
+			continue
+		}
+		replicas := *deploy.Spec.Replicas
+		if deploy.Status.AvailableReplicas == replicas && deploy.Status.ObservedGeneration == deploy.Generation {
+			continue
+		}
+		deploy.Status.ObservedGeneration = deploy.Generation
+		deploy.Status.Replicas, deploy.Status.UpdatedReplicas = replicas, replicas
+		deploy.Status.ReadyReplicas, deploy.Status.AvailableReplicas = replicas, replicas
+		Expect(k8sClient.Status().Update(s.ctx, deploy)).To(Succeed())
+	}
+}
+
+// advance clock을 d만큼 진행하고 실행 시간이 된 Cron job을 실행한 후 reconcile합니다.
+func (s *simulation) advance(d time.Duration) {
+	s.clock.Step(d)
+	s.cron.RunDue()
+	s.reconcile()
+}
+
+// command Canary annotation으로 Command를 전달하고 reconcile합니다.
+func (s *simulation) command(cmd string) {
+	s.commandStep(cmd, "")
+}
+
+// commandStep step annotation과 함께 Command를 전달하고 reconcile합니다.
+func (s *simulation) commandStep(cmd, step string) {
+	canary := s.canary()
+	if canary.Annotations == nil {
+		canary.Annotations = map[string]string{}
+	}
+	canary.Annotations[Command] = cmd
+	if step != "" {
+		canary.Annotations[AnnotationStep] = step
+	}
+	Expect(k8sClient.Update(s.ctx, canary)).To(Succeed())
+	s.reconcile()
+}
+
+// crash new Deployment에 재시작된 pod를 생성하고 reconcile합니다.
+func (s *simulation) crash() {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.key.Namespace,
+			Name:      s.deploys[1] + "-crash",
+			Labels:    s.deployment(s.deploys[1]).Spec.Selector.MatchLabels,
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}},
+	}
+	Expect(k8sClient.Create(s.ctx, pod)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(s.ctx, pod)).To(Succeed())
+	})
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", RestartCount: 1}}
+	Expect(k8sClient.Status().Update(s.ctx, pod)).To(Succeed())
+
+	s.reconcile()
+}
+
+// deleteCanary Canary를 삭제하고 finalizer가 제거될 때까지 reconcile합니다.
+func (s *simulation) deleteCanary() {
+	canary := &canaryv1alpha1.Canary{}
+	if err := k8sClient.Get(s.ctx, s.key, canary); apierrors.IsNotFound(err) {
+		return
+	}
+	Expect(k8sClient.Delete(s.ctx, canary)).To(Succeed())
+	_, err := s.reconciler.Reconcile(s.ctx, ctrl.Request{NamespacedName: s.key})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(apierrors.IsNotFound(k8sClient.Get(s.ctx, s.key, canary))).To(BeTrue())
+}
+
+// deleteDeployment Deployment를 삭제합니다.
+func (s *simulation) deleteDeployment(name string) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: s.key.Namespace, Name: name}}
+	Expect(client.IgnoreNotFound(k8sClient.Delete(s.ctx, deploy))).To(Succeed())
+}
+
+// delete Canary와 Deployment를 모두 삭제합니다.
+func (s *simulation) delete() {
+	s.deleteCanary()
+	for _, name := range s.deploys {
+		s.deleteDeployment(name)
+	}
+}
+
+func (s *simulation) canary() *canaryv1alpha1.Canary {
+	canary := &canaryv1alpha1.Canary{}
+	Expect(k8sClient.Get(s.ctx, s.key, canary)).To(Succeed())
+	return canary
+}
+
+func (s *simulation) deployment(name string) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{}
+	Expect(k8sClient.Get(s.ctx, client.ObjectKey{Namespace: s.key.Namespace, Name: name}, deploy)).To(Succeed())
+	return deploy
+}
+
+// expect Canary 상태, 단계와 old, new Deployment replicas를 확인합니다.
+func (s *simulation) expect(state canaryv1alpha1.CanaryPhase, step, oldReplicas, newReplicas int32) {
+	GinkgoHelper()
+	canary := s.canary()
+	Expect(canary.Status.State).To(Equal(state), canary.Status.Message)
+	Expect(canary.Status.CurrentStep).To(Equal(step))
+	Expect(*s.deployment(s.deploys[0]).Spec.Replicas).To(Equal(oldReplicas))
+	Expect(*s.deployment(s.deploys[1]).Spec.Replicas).To(Equal(newReplicas))
+}
+
+var _ = Describe("Canary simulation", func() {
+	spec := func() canaryv1alpha1.CanarySpec {
+		return canaryv1alpha1.CanarySpec{TotalReplicas: 10, StepReplicas: 2, CronSchedule: "* * * * *"}
+	}
+
+	It("should progress through all steps by the cron schedule", func() {
+		s := newSimulation("sim-progress", spec())
+		s.expect(canaryv1alpha1.PhasePending, 0, 10, 0)
+
+		s.command(canaryv1alpha1.CommandApply)
+		s.expect(canaryv1alpha1.PhaseProgressing, 0, 10, 0)
+		Expect(s.canary().Status.NextStepTime.Time).To(BeTemporally("==", s.clock.Now().Add(time.Minute)))
+
+		for step := int32(1); step < 5; step++ {
+			s.advance(time.Minute)
+			By(fmt.Sprintf("step %d", step))
+			s.expect(canaryv1alpha1.PhaseProgressing, step, 10-2*step, 2*step)
+		}
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseCompleted, 5, 0, 10)
+	})
+
+	It("should progress by the step interval once the step is healthy", func() {
+		canarySpec := spec()
+		canarySpec.StepInterval = &metav1.Duration{Duration: 15 * time.Minute}
+		s := newSimulation("sim-interval", canarySpec)
+
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(14 * time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 0, 10, 0)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 1, 8, 2)
+	})
+
+	It("should stop, resume and promote by commands", func() {
+		s := newSimulation("sim-commands", spec())
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+
+		s.command(canaryv1alpha1.CommandStop)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhasePaused, 1, 8, 2)
+
+		s.command(canaryv1alpha1.CommandPromote)
+		s.expect(canaryv1alpha1.PhasePaused, 2, 6, 4)
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 3, 4, 6)
+
+		s.command(canaryv1alpha1.CommandCompletion)
+		s.expect(canaryv1alpha1.PhaseCompleted, 5, 0, 10)
+	})
+
+	It("should roll back by command and retry from the failed step", func() {
+		s := newSimulation("sim-rollback", spec())
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+		s.advance(time.Minute)
+
+		s.command(canaryv1alpha1.CommandRollback)
+		s.expect(canaryv1alpha1.PhaseRolledBack, 0, 10, 0)
+		Expect(s.canary().Status.LastFailedStep).To(Equal(int32(2)))
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseRolledBack, 0, 10, 0)
+
+		s.command(canaryv1alpha1.CommandRetry)
+		s.expect(canaryv1alpha1.PhaseProgressing, 2, 6, 4)
+	})
+
+	It("should roll back when the new deployment crashes", func() {
+		canarySpec := spec()
+		canarySpec.EnableRollback = true
+		s := newSimulation("sim-crash", canarySpec)
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseProgressing, 1, 8, 2)
+
+		rollbacks := rollbackCounter.WithLabelValues(s.key.Namespace, s.key.Name, RollbackReasonCrash)
+		before := testutil.ToFloat64(rollbacks)
+		s.crash()
+		s.expect(canaryv1alpha1.PhaseRolledBack, 0, 10, 0)
+
+		// 종료 중인 crash pod가 남아 있어도 다시 롤백하지 않습니다.
+		s.advance(time.Minute)
+		s.expect(canaryv1alpha1.PhaseRolledBack, 0, 10, 0)
+		Expect(s.canary().Status.LastFailedStep).To(Equal(int32(1)))
+		Expect(testutil.ToFloat64(rollbacks) - before).To(Equal(1.0))
+	})
+
+	It("should roll back one step per interval with the stepped strategy", func() {
+		canarySpec := spec()
+		canarySpec.Rollback = &canaryv1alpha1.RollbackSpec{
+			Strategy: canaryv1alpha1.RollbackStepped,
+			Interval: &metav1.Duration{Duration: 30 * time.Second},
+		}
+		s := newSimulation("sim-stepped", canarySpec)
+		s.command(canaryv1alpha1.CommandApply)
+		s.advance(time.Minute)
+		s.advance(time.Minute)
+
+		s.command(canaryv1alpha1.CommandRollback)
+		s.expect(canaryv1alpha1.PhaseRollingBack, 2, 6, 4)
+		s.advance(30 * time.Second)
+		s.expect(canaryv1alpha1.PhaseRollingBack, 1, 8, 2)
+		s.advance(30 * time.Second)
+		s.expect(canaryv1alpha1.PhaseRolledBack, 0, 10, 0)
+	})
+})
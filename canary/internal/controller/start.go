@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// autoStart spec.autoStart이면 즉시, spec.startAt이면 지정한 시간에 apply Command와 같이 Canary를 시작합니다.
+// 한 번 시작된 Canary는 다시 자동으로 시작하지 않으며, 시작 시간까지 남은 시간을 반환합니다.
+func (r *CanaryReconciler) autoStart(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) time.Duration {
+	if canary.Status.StartTime != nil || (!canary.Spec.AutoStart && canary.Spec.StartAt == nil) {
+		return 0
+	}
+	if phase(canary) != canaryv1alpha1.PhasePending {
+		return 0
+	}
+
+	message := "Canary is started automatically"
+	if canary.Spec.StartAt != nil {
+		if wait := canary.Spec.StartAt.Sub(r.now()); wait > 0 {
+			return wait
+		}
+		message = "Canary is started at the scheduled time " + canary.Spec.StartAt.Format(time.RFC3339)
+	}
+
+	var result commandResult
+	var cmdErr error
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		result, cmdErr = r.runCommand(canary, canaryv1alpha1.CommandApply, nil)
+		return cmdErr == nil
+	}); err != nil {
+		logger.Error(err, "[Reconcile] Failed to update Canary status", "namespace", canary.Namespace, "name", canary.Name)
+		return 0
+	}
+	if cmdErr != nil {
+		logger.Info("[Reconcile] Canary is not started automatically", "namespace", canary.Namespace, "name", canary.Name, "reason", cmdErr.Error())
+		return 0
+	}
+	recordStatus(canary)
+	r.event(canary, corev1.EventTypeNormal, "Started", message)
+	r.finishCommand(ctx, logger, canary, result, oldDeploy, newDeploy)
+	logger.Info("[Reconcile] "+message, "namespace", canary.Namespace, "name", canary.Name)
+
+	return 0
+}
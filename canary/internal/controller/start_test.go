@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Auto start", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var (
+		reconciler *CanaryReconciler
+		fakeClock  *clocktesting.FakeClock
+	)
+
+	newCanary := func(name string, autoStart bool, startAt *metav1.Time) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.AutoStart, spec.StartAt = autoStart, startAt
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, nil)
+	}
+
+	start := func(canary *canaryv1alpha1.Canary) time.Duration {
+		wait := reconciler.autoStart(ctx, logger, canary, &appsv1.Deployment{}, &appsv1.Deployment{})
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		return wait
+	}
+
+	BeforeEach(func() {
+		reconciler, fakeClock = newTestReconciler()
+	})
+
+	It("should stay pending without autoStart and startAt", func() {
+		canary := newCanary("start-manual", false, nil)
+		Expect(start(canary)).To(BeZero())
+		Expect(canary.Status.State).To(BeEmpty())
+		Expect(canary.Status.StartTime).To(BeNil())
+	})
+
+	It("should start immediately upon creation with autoStart", func() {
+		canary := newCanary("start-auto", true, nil)
+		Expect(start(canary)).To(BeZero())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+		Expect(canary.Status.StartTime).NotTo(BeNil())
+	})
+
+	It("should wait until startAt", func() {
+		startAt := metav1.NewTime(fakeClock.Now().Add(time.Hour))
+		canary := newCanary("start-scheduled", false, &startAt)
+		Expect(start(canary)).To(Equal(time.Hour))
+		Expect(canary.Status.State).To(BeEmpty())
+
+		fakeClock.Step(time.Hour)
+		Expect(start(canary)).To(BeZero())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePromoting))
+	})
+
+	It("should not start again once paused", func() {
+		canary := newCanary("start-paused", true, nil)
+		start(canary)
+		canary.Status.State = canaryv1alpha1.PhasePaused
+		Expect(k8sClient.Status().Update(ctx, canary)).To(Succeed())
+
+		start(canary)
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+	})
+})
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+	"github.com/k8shuginn/canary-operator/internal/cloudevent"
+)
+
+const (
+	// ConditionStepBlocked 다음 단계로 진행할 수 없는 상태를 나타내는 condition
+	ConditionStepBlocked = "StepBlocked"
+
+	// stepBlockedRecheck 단계 진행이 막혔을 때 다시 확인하는 주기
+	stepBlockedRecheck = time.Minute
+)
+
+// maxStep Canary의 마지막 단계를 반환합니다.
+func maxStep(canary *canaryv1alpha1.Canary) int32 {
+	if canary.Spec.StepReplicas <= 0 {
+		return 0
+	}
+
+	return canary.Spec.TotalReplicas / canary.Spec.StepReplicas
+}
+
+// advanceStep Canary를 다음 단계로 진행합니다. cronSchedule과 stepInterval 모두 이 함수로 단계를 진행합니다.
+// 진행 중이 아니거나 마지막 단계이거나 단계 진행이 막혀 있으면 진행하지 않고 false를 반환하며, 막힌 이유는 StepBlocked condition에 기록합니다.
+// holidays ConfigMap은 cache를 거치지 않도록 reader로 읽습니다.
+// 단계는 resourceVersion을 확인하는 patch로 변경하므로, 동시에 rollback이나 stop이 적용되면 최신 Canary를 다시 확인하여 진행하지 않습니다.
+func advanceStep(ctx context.Context, c client.Client, reader client.Reader, emitter *cloudevent.Emitter, canary *canaryv1alpha1.Canary, now time.Time) (bool, error) {
+	var advanced bool
+	var prevStart *metav1.Time
+	err := patchStatus(ctx, c, canary, func() bool {
+		advanced = false
+		if !isActive(canary) || canary.Status.CurrentStep >= maxStep(canary) {
+			return false
+		}
+
+		if reason, message := stepBlocked(ctx, c, reader, canary, now); reason != "" {
+			// 같은 이유로 이미 막혀 있으면 status를 다시 업데이트하지 않습니다.
+			if cond := meta.FindStatusCondition(canary.Status.Conditions, ConditionStepBlocked); cond != nil &&
+				cond.Status == metav1.ConditionTrue && cond.Reason == reason && cond.Message == message {
+				return false
+			}
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionStepBlocked, Status: metav1.ConditionTrue, Reason: reason, Message: message,
+			})
+			return true
+		}
+		if meta.FindStatusCondition(canary.Status.Conditions, ConditionStepBlocked) != nil {
+			meta.SetStatusCondition(&canary.Status.Conditions, metav1.Condition{
+				Type: ConditionStepBlocked, Status: metav1.ConditionFalse, Reason: "Allowed", Message: "Step advancement is allowed",
+			})
+		}
+
+		advanced, prevStart = true, canary.Status.StepStartTime
+		canary.Status.CurrentStep++
+		canary.Status.StepStartTime = &metav1.Time{Time: now}
+		canary.Status.NextStepTime = nil
+		return true
+	})
+	if err != nil || !advanced {
+		return false, err
+	}
+
+	if prevStart != nil {
+		recordStepDuration(canary.Namespace, canary.Name, now.Sub(prevStart.Time))
+	}
+	recordAnalysis(canary.Namespace, canary.Name, AnalysisResultHealthy)
+	emitStep(ctx, c, emitter, canary, AnalysisResultHealthy)
+
+	return true, nil
+}
+
+// stepBlocked 단계 진행을 막는 이유가 있으면 condition reason과 message를 반환합니다.
+// ReleaseFreeze를 window보다 먼저 확인합니다.
+func stepBlocked(ctx context.Context, c, reader client.Reader, canary *canaryv1alpha1.Canary, now time.Time) (string, string) {
+	reason, message, err := freezeBlocked(ctx, c, canary, now)
+	if err != nil {
+		// freeze를 확인할 수 없으면 안전하게 진행하지 않습니다.
+		return ReasonReleaseFreeze, fmt.Sprintf("Failed to check release freezes: %v", err)
+	}
+	if reason != "" {
+		return reason, message
+	}
+
+	return windowBlocked(ctx, reader, canary, now)
+}
+
+// emitStep step 변경 CloudEvent를 전송합니다. analysisResult가 있으면 분석 결과 CloudEvent를 먼저 전송합니다.
+func emitStep(ctx context.Context, c client.Client, emitter *cloudevent.Emitter, canary *canaryv1alpha1.Canary, analysisResult string) {
+	if emitter == nil {
+		return
+	}
+
+	oldDeploy, newDeploy := &appsv1.Deployment{}, &appsv1.Deployment{}
+	_ = c.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Spec.OldDeployment}, oldDeploy)
+	_ = c.Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: canary.Spec.NewDeployment}, newDeploy)
+	if analysisResult != "" {
+		emitAnalysis(ctx, emitter, analysisResult, canary, oldDeploy, newDeploy)
+	}
+	emit(ctx, emitter, cloudevent.TypeStep, canary, oldDeploy, newDeploy)
+}
+
+// stepByInterval stepInterval을 사용하는 Canary를 현재 단계가 healthy 상태가 된 시간부터 interval 후에 다음 단계로 진행합니다.
+// 다음 단계까지 남은 시간을 반환합니다. 0이면 requeue하지 않습니다.
+func (r *CanaryReconciler) stepByInterval(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) time.Duration {
+	if canary.Spec.StepInterval == nil || !isActive(canary) || canary.Status.CurrentStep >= maxStep(canary) {
+		return 0
+	}
+
+	now := r.now()
+	if canary.Status.NextStepTime == nil {
+		// Deployment status가 변경되면 다시 reconcile되므로 healthy 상태가 될 때까지 기다립니다.
+		if !isStepHealthy(canary, oldDeploy, newDeploy) {
+			return 0
+		}
+		next := metav1.NewTime(now.Add(canary.Spec.StepInterval.Duration))
+		if err := patchStatus(ctx, r.Client, canary, func() bool {
+			canary.Status.NextStepTime = &next
+			return true
+		}); err != nil {
+			logger.Error(err, "[Reconcile] Failed to update Canary next step time", "namespace", canary.Namespace, "name", canary.Name)
+			return 0
+		}
+		return canary.Spec.StepInterval.Duration
+	}
+
+	if wait := canary.Status.NextStepTime.Sub(now); wait > 0 {
+		return wait
+	}
+	ok, err := advanceStep(ctx, r.Client, r.apiReader(), r.Emitter, canary, now)
+	if err != nil {
+		logger.Error(err, "[Reconcile] Failed to advance Canary step", "namespace", canary.Namespace, "name", canary.Name)
+		return 0
+	}
+	if !ok {
+		// window가 열리면 자동으로 다시 진행합니다.
+		return stepBlockedRecheck
+	}
+	logger.Info("[Reconcile] Canary is advanced by step interval", "namespace", canary.Namespace, "name", canary.Name, "step", canary.Status.CurrentStep)
+
+	return 0
+}
+
+// isRolledOut old Deployment가 0으로 줄고 new Deployment의 전체 replicas가 available 상태인지 확인합니다.
+// surge 정책에서는 old Deployment가 줄어들기 전에 new Deployment가 먼저 전체 replicas에 도달하므로 new replicas만으로 완료하지 않습니다.
+func isRolledOut(canary *canaryv1alpha1.Canary, newDeploy *appsv1.Deployment) bool {
+	total := totalReplicas(canary)
+	return canary.Status.OldReplicas == 0 && canary.Status.NewReplicas == total &&
+		newDeploy.Status.AvailableReplicas >= total
+}
+
+// isStepHealthy 현재 단계의 replicas로 scale되고 두 Deployment의 pod가 모두 available 상태인지 확인합니다.
+func isStepHealthy(canary *canaryv1alpha1.Canary, oldDeploy, newDeploy *appsv1.Deployment) bool {
+	if oldReplicas, newReplicas := desiredReplicas(canary); !canary.Spec.EnableHPA &&
+		(canary.Status.OldReplicas != oldReplicas || canary.Status.NewReplicas != newReplicas) {
+		return false
+	}
+	for _, deploy := range []*appsv1.Deployment{oldDeploy, newDeploy} {
+		if deploy.Spec.Replicas != nil && deploy.Status.AvailableReplicas < *deploy.Spec.Replicas {
+			return false
+		}
+	}
+
+	return true
+}
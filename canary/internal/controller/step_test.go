@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Step interval", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var (
+		reconciler *CanaryReconciler
+		fakeClock  *clocktesting.FakeClock
+	)
+
+	newCanary := func(name string) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.CronSchedule, spec.StepInterval = "", &metav1.Duration{Duration: 15 * time.Minute}
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, &canaryv1alpha1.CanaryStatus{
+			State:       canaryv1alpha1.PhaseProgressing,
+			CurrentStep: 1,
+			OldReplicas: 8,
+			NewReplicas: 2,
+		})
+	}
+
+	BeforeEach(func() {
+		reconciler, fakeClock = newTestReconciler()
+	})
+
+	It("should wait until the current step is healthy", func() {
+		canary := newCanary("interval-unhealthy")
+		Expect(reconciler.stepByInterval(ctx, logger, canary, testDeployment("old", 8, 8), testDeployment("new", 2, 1))).To(BeZero())
+		Expect(canary.Status.NextStepTime).To(BeNil())
+	})
+
+	It("should schedule the next step once the current step is healthy", func() {
+		canary := newCanary("interval-healthy")
+		Expect(reconciler.stepByInterval(ctx, logger, canary, testDeployment("old", 8, 8), testDeployment("new", 2, 2))).To(Equal(15 * time.Minute))
+		Expect(canary.Status.NextStepTime).NotTo(BeNil())
+		Expect(canary.Status.NextStepTime.Time).To(BeTemporally("==", fakeClock.Now().Add(15*time.Minute)))
+		Expect(canary.Status.CurrentStep).To(Equal(int32(1)))
+	})
+
+	It("should advance to the next step when the interval has elapsed", func() {
+		canary := newCanary("interval-elapsed")
+		past := metav1.NewTime(fakeClock.Now().Add(-time.Minute))
+		canary.Status.NextStepTime = &past
+		Expect(reconciler.stepByInterval(ctx, logger, canary, testDeployment("old", 8, 8), testDeployment("new", 2, 2))).To(BeZero())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(canary), canary)).To(Succeed())
+		Expect(canary.Status.CurrentStep).To(Equal(int32(2)))
+		Expect(canary.Status.NextStepTime).To(BeNil())
+		Expect(canary.Status.StepStartTime.Time).To(BeTemporally("==", fakeClock.Now()))
+	})
+})
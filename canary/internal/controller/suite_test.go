@@ -17,16 +17,21 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -88,3 +93,60 @@ var _ = AfterSuite(func() {
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
+
+// testStart fake clock의 시작 시간
+var testStart = time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+// newTestReconciler fake clock과 manual Cron을 사용하는 CanaryReconciler를 반환합니다.
+// Cron job은 RunDue를 호출할 때만 실행되므로 실제 시간에 따라 테스트 결과가 달라지지 않습니다.
+func newTestReconciler() (*CanaryReconciler, *clocktesting.FakeClock) {
+	fakeClock := clocktesting.NewFakeClock(testStart)
+	return &CanaryReconciler{
+		Client: k8sClient,
+		Scheme: k8sClient.Scheme(),
+		Cr:     NewManualCron(k8sClient, k8sClient, nil, fakeClock),
+		Clock:  fakeClock,
+	}, fakeClock
+}
+
+// testDeployment replicas와 available replicas만 있는 Deployment를 반환합니다. API server에는 생성하지 않습니다.
+func testDeployment(name string, replicas, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+// testCanarySpec old, new Deployment의 10개 replicas를 2개씩 옮기는 Canary spec을 반환합니다.
+func testCanarySpec() canaryv1alpha1.CanarySpec {
+	return canaryv1alpha1.CanarySpec{
+		OldDeployment: "old",
+		NewDeployment: "new",
+		TotalReplicas: 10,
+		StepReplicas:  2,
+		CronSchedule:  "* * * * *",
+	}
+}
+
+// createTestCanary default namespace에 Canary를 생성하고 spec이 끝나면 삭제합니다.
+// status가 있으면 생성한 Canary의 status도 저장합니다.
+func createTestCanary(
+	ctx context.Context,
+	objectMeta metav1.ObjectMeta,
+	spec canaryv1alpha1.CanarySpec,
+	status *canaryv1alpha1.CanaryStatus,
+) *canaryv1alpha1.Canary {
+	objectMeta.Namespace = "default"
+	canary := &canaryv1alpha1.Canary{ObjectMeta: objectMeta, Spec: spec}
+	Expect(k8sClient.Create(ctx, canary)).To(Succeed())
+	DeferCleanup(func() {
+		Expect(k8sClient.Delete(ctx, canary)).To(Succeed())
+	})
+
+	if status != nil {
+		canary.Status = *status
+		Expect(k8sClient.Status().Update(ctx, canary)).To(Succeed())
+	}
+	return canary
+}
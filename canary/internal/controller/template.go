@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+// checkNewTemplate new Deployment pod template이 변경되었는지 확인하고 templateChangePolicy에 따라 처리합니다.
+// 처음 확인한 template hash는 status에 기록만 합니다.
+func (r *CanaryReconciler) checkNewTemplate(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	hash := templateHash(&newDeploy.Spec.Template)
+	if canary.Status.NewTemplateHash == hash {
+		return
+	}
+
+	var message string
+	paused := false
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		message, paused = "", false
+		if canary.Status.NewTemplateHash == hash {
+			return false
+		}
+
+		if canary.Status.NewTemplateHash != "" {
+			policy := canary.Spec.TemplateChangePolicy
+			message = fmt.Sprintf("pod template of %s is changed from %s to %s", newDeploy.Name, canary.Status.NewTemplateHash, hash)
+			logger.Info("[Reconcile] New deployment template is changed", "namespace", canary.Namespace, "name", canary.Name, "policy", policy, "hash", hash)
+
+			// 완료된 Canary는 이미 모든 replicas가 new Deployment로 전환되어 있으므로 그대로 진행합니다.
+			switch {
+			case policy == canaryv1alpha1.TemplateChangeRestart && (isActive(canary) || isIdle(canary)) && canary.Status.CurrentStep > 0:
+				canary.Status.CurrentStep = 0
+				// runCommand처럼 단계가 변경되면 deadline과 stepInterval을 새 단계부터 다시 계산합니다.
+				canary.Status.StepStartTime = &metav1.Time{Time: r.now()}
+				canary.Status.NextStepTime = nil
+				canary.Status.Message = fmt.Sprintf("[%s] Canary is restarted from step 0: %s", r.now().Format(time.RFC3339), message)
+				message += ", restarted from step 0"
+			case policy == canaryv1alpha1.TemplateChangePause && isActive(canary):
+				paused = true
+				canary.Status.State = canaryv1alpha1.PhasePaused
+				canary.Status.Message = fmt.Sprintf("[%s] Canary is stopped for approval: %s", r.now().Format(time.RFC3339), message)
+				message += ", paused"
+			}
+		}
+
+		// template 변경은 drift로 처리하지 않습니다.
+		canary.Status.NewTemplateHash = hash
+		canary.Status.NewObservedGeneration = newDeploy.Generation
+		return true
+	}); err != nil {
+		logger.Error(err, "[Reconcile] Failed to update Canary template hash", "namespace", canary.Namespace, "name", canary.Name)
+		return
+	}
+
+	if message != "" {
+		r.event(canary, corev1.EventTypeNormal, "TemplateChanged", message)
+	}
+	if paused {
+		r.Cr.Delete(canary.Namespace, canary.Name)
+		r.publish(ctx, canary, canaryv1alpha1.NotificationPaused, oldDeploy, newDeploy)
+	}
+}
+
+// templateHash pod template의 hash를 반환합니다.
+func templateHash(template *corev1.PodTemplateSpec) string {
+	hasher := fnv.New32a()
+	data, _ := json.Marshal(template)
+	_, _ = hasher.Write(data)
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
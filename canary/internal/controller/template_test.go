@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("New deployment template change", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	var reconciler *CanaryReconciler
+
+	deployment := func(image string) *appsv1.Deployment {
+		deploy := testDeployment("new", 0, 0)
+		deploy.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: image}}
+		return deploy
+	}
+
+	newCanary := func(name string, policy canaryv1alpha1.TemplateChangePolicy) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.TemplateChangePolicy = policy
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, &canaryv1alpha1.CanaryStatus{
+			State:       canaryv1alpha1.PhaseProgressing,
+			CurrentStep: 3,
+		})
+		reconciler.checkNewTemplate(ctx, logger, canary, &appsv1.Deployment{}, deployment("app:v2"))
+		Expect(canary.Status.NewTemplateHash).To(Equal(templateHash(&deployment("app:v2").Spec.Template)))
+		return canary
+	}
+
+	BeforeEach(func() {
+		reconciler, _ = newTestReconciler()
+	})
+
+	It("should restart from step 0", func() {
+		canary := newCanary("template-restart", canaryv1alpha1.TemplateChangeRestart)
+		reconciler.checkNewTemplate(ctx, logger, canary, &appsv1.Deployment{}, deployment("app:v3"))
+		Expect(canary.Status.CurrentStep).To(BeZero())
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseProgressing))
+		Expect(canary.Status.NewTemplateHash).To(Equal(templateHash(&deployment("app:v3").Spec.Template)))
+	})
+
+	It("should restart the step timers with the step", func() {
+		canary := newCanary("template-restart-timers", canaryv1alpha1.TemplateChangeRestart)
+		canary.Status.StepStartTime = &metav1.Time{Time: testStart.Add(-time.Hour)}
+		canary.Status.NextStepTime = &metav1.Time{Time: testStart.Add(-time.Minute)}
+		reconciler.checkNewTemplate(ctx, logger, canary, &appsv1.Deployment{}, deployment("app:v3"))
+		Expect(canary.Status.CurrentStep).To(BeZero())
+		Expect(canary.Status.StepStartTime.Time).To(BeTemporally("==", testStart))
+		Expect(canary.Status.NextStepTime).To(BeNil())
+	})
+
+	It("should pause for approval", func() {
+		canary := newCanary("template-pause", canaryv1alpha1.TemplateChangePause)
+		reconciler.checkNewTemplate(ctx, logger, canary, &appsv1.Deployment{}, deployment("app:v3"))
+		Expect(canary.Status.CurrentStep).To(Equal(int32(3)))
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhasePaused))
+	})
+
+	It("should continue", func() {
+		canary := newCanary("template-continue", canaryv1alpha1.TemplateChangeContinue)
+		reconciler.checkNewTemplate(ctx, logger, canary, &appsv1.Deployment{}, deployment("app:v3"))
+		Expect(canary.Status.CurrentStep).To(Equal(int32(3)))
+		Expect(canary.Status.State).To(Equal(canaryv1alpha1.PhaseProgressing))
+	})
+})
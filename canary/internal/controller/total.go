@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+
+// totalReplicas Canary가 나누는 전체 replicas를 반환합니다. 다시 계산된 값이 있으면 그 값을 사용합니다.
+func totalReplicas(canary *canaryv1alpha1.Canary) int32 {
+	if isDynamicTotal(canary) && canary.Status.TotalReplicas > 0 {
+		return canary.Status.TotalReplicas
+	}
+
+	return canary.Spec.TotalReplicas
+}
+
+// isDynamicTotal 전체 replicas가 dynamicTotal이나 driftPolicy adopt로 다시 계산되는지 확인합니다.
+func isDynamicTotal(canary *canaryv1alpha1.Canary) bool {
+	return canary.Spec.DynamicTotal != nil || canary.Spec.DriftPolicy == canaryv1alpha1.DriftAdopt
+}
+
+// isObservedTotal 전체 replicas가 Deployment replicas 합으로 다시 계산되는지 확인합니다.
+func isObservedTotal(canary *canaryv1alpha1.Canary) bool {
+	return canary.Spec.DynamicTotal != nil && canary.Spec.DynamicTotal.Source != canaryv1alpha1.DynamicTotalConfigMap
+}
+
+// syncTotalReplicas dynamicTotal이면 전체 replicas를 다시 계산하여 status에 기록합니다.
+// observed는 Canary가 마지막으로 적용한 replicas 이후 Deployment에 직접 변경된 replicas를 전체 replicas에 반영합니다.
+func (r *CanaryReconciler) syncTotalReplicas(
+	ctx context.Context,
+	logger logr.Logger,
+	canary *canaryv1alpha1.Canary,
+	oldDeploy, newDeploy *appsv1.Deployment,
+) {
+	if canary.Spec.DynamicTotal == nil {
+		return
+	}
+
+	var total int32
+	switch canary.Spec.DynamicTotal.Source {
+	case canaryv1alpha1.DynamicTotalConfigMap:
+		value, err := r.configMapTotal(ctx, canary)
+		if err != nil {
+			logger.Error(err, "[Reconcile] Failed to read total replicas from ConfigMap", "namespace", canary.Namespace, "name", canary.Name)
+			return
+		}
+		total = value
+	default:
+		if canary.Status.TotalReplicas == 0 {
+			total = *oldDeploy.Spec.Replicas + *newDeploy.Spec.Replicas
+		} else {
+			total = canary.Status.TotalReplicas +
+				(*oldDeploy.Spec.Replicas - canary.Status.OldReplicas) + (*newDeploy.Spec.Replicas - canary.Status.NewReplicas)
+		}
+	}
+
+	if total <= 0 || total == canary.Status.TotalReplicas {
+		return
+	}
+
+	logger.Info("[Reconcile] Total replicas are changed", "namespace", canary.Namespace, "name", canary.Name,
+		"from", canary.Status.TotalReplicas, "to", total)
+	if err := patchStatus(ctx, r.Client, canary, func() bool {
+		canary.Status.TotalReplicas = total
+		return true
+	}); err != nil {
+		logger.Error(err, "[Reconcile] Failed to update Canary total replicas", "namespace", canary.Namespace, "name", canary.Name)
+	}
+}
+
+// configMapTotal ConfigMap key에 기록된 전체 replicas를 읽습니다.
+func (r *CanaryReconciler) configMapTotal(ctx context.Context, canary *canaryv1alpha1.Canary) (int32, error) {
+	ref := canary.Spec.DynamicTotal.ConfigMapKeyRef
+	if ref == nil {
+		return 0, fmt.Errorf("configMapKeyRef is required for the configMap source")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.apiReader().Get(ctx, client.ObjectKey{Namespace: canary.Namespace, Name: ref.Name}, configMap); err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseInt(configMap.Data[ref.Key], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid total replicas %q in ConfigMap %s", configMap.Data[ref.Key], ref.Name)
+	}
+
+	return int32(value), nil
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Dynamic total replicas", func() {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+
+	newCanary := func(name string, dynamicTotal *canaryv1alpha1.DynamicTotalSpec) *canaryv1alpha1.Canary {
+		spec := testCanarySpec()
+		spec.DynamicTotal = dynamicTotal
+		return createTestCanary(ctx, metav1.ObjectMeta{Name: name}, spec, nil)
+	}
+
+	DescribeTable("preserves the step ratio",
+		func(total, step, expectedOld, expectedNew int32) {
+			canary := &canaryv1alpha1.Canary{
+				Spec: canaryv1alpha1.CanarySpec{
+					TotalReplicas: 10,
+					StepReplicas:  2,
+					DynamicTotal:  &canaryv1alpha1.DynamicTotalSpec{},
+				},
+				Status: canaryv1alpha1.CanaryStatus{CurrentStep: step, TotalReplicas: total},
+			}
+			oldReplicas, newReplicas := desiredReplicas(canary)
+			Expect(oldReplicas).To(Equal(expectedOld))
+			Expect(newReplicas).To(Equal(expectedNew))
+		},
+		Entry("uses the spec total until recomputed", int32(0), int32(1), int32(8), int32(2)),
+		Entry("scales the ratio with the total", int32(15), int32(1), int32(12), int32(3)),
+		Entry("moves every replica at the last step", int32(15), int32(5), int32(0), int32(15)),
+	)
+
+	It("should keep every replica on the old deployment without any step", func() {
+		canary := &canaryv1alpha1.Canary{
+			Spec: canaryv1alpha1.CanarySpec{
+				TotalReplicas: 1,
+				StepReplicas:  2,
+				DynamicTotal:  &canaryv1alpha1.DynamicTotalSpec{},
+			},
+			Status: canaryv1alpha1.CanaryStatus{TotalReplicas: 3},
+		}
+		oldReplicas, newReplicas := desiredReplicas(canary)
+		Expect(oldReplicas).To(Equal(int32(3)))
+		Expect(newReplicas).To(BeZero())
+	})
+
+	It("should adopt replicas scaled out of band", func() {
+		reconciler, _ := newTestReconciler()
+		canary := newCanary("observed-total", &canaryv1alpha1.DynamicTotalSpec{Source: canaryv1alpha1.DynamicTotalObserved})
+
+		reconciler.syncTotalReplicas(ctx, logger, canary, testDeployment("old", 8, 8), testDeployment("new", 2, 2))
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(10)))
+
+		canary.Status.OldReplicas, canary.Status.NewReplicas = 8, 2
+		reconciler.syncTotalReplicas(ctx, logger, canary, testDeployment("old", 13, 13), testDeployment("new", 2, 2))
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(15)))
+	})
+
+	It("should read the total from a ConfigMap", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "canary-total"},
+			Data:       map[string]string{"replicas": "20"},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+		})
+
+		reconciler, _ := newTestReconciler()
+		canary := newCanary("configmap-total", &canaryv1alpha1.DynamicTotalSpec{
+			Source: canaryv1alpha1.DynamicTotalConfigMap,
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "canary-total"},
+				Key:                  "replicas",
+			},
+		})
+
+		reconciler.syncTotalReplicas(ctx, logger, canary, testDeployment("old", 8, 8), testDeployment("new", 2, 2))
+		Expect(canary.Status.TotalReplicas).To(Equal(int32(20)))
+	})
+})
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	cronv3 "github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+const (
+	// StepBlocked condition reason
+	ReasonOutsideAllowedWindow = "OutsideAllowedWindow"
+	ReasonBlackoutWindow       = "BlackoutWindow"
+	ReasonHoliday              = "Holiday"
+	ReasonInvalidWindow        = "InvalidWindow"
+)
+
+// windowBlocked 현재 시간이 blackoutWindows, holidays에 포함되거나 allowedWindows를 벗어났는지 확인합니다.
+// 단계 진행을 막는 경우 condition reason과 message를 반환합니다.
+func windowBlocked(ctx context.Context, c client.Reader, canary *canaryv1alpha1.Canary, now time.Time) (string, string) {
+	for i, window := range canary.Spec.BlackoutWindows {
+		in, err := inWindow(window, now)
+		if err != nil {
+			return ReasonInvalidWindow, fmt.Sprintf("blackout window %q is invalid: %v", windowName(window, i), err)
+		}
+		if in {
+			return ReasonBlackoutWindow, fmt.Sprintf("Step advancement is paused by blackout window %q", windowName(window, i))
+		}
+	}
+
+	if canary.Spec.Holidays != nil {
+		holiday, err := isHoliday(ctx, c, canary, now)
+		if err != nil {
+			return ReasonInvalidWindow, fmt.Sprintf("holidays are invalid: %v", err)
+		}
+		if holiday != "" {
+			return ReasonHoliday, fmt.Sprintf("Step advancement is paused on holiday %s", holiday)
+		}
+	}
+
+	if len(canary.Spec.AllowedWindows) == 0 {
+		return "", ""
+	}
+	for i, window := range canary.Spec.AllowedWindows {
+		in, err := inWindow(window, now)
+		if err != nil {
+			return ReasonInvalidWindow, fmt.Sprintf("allowed window %q is invalid: %v", windowName(window, i), err)
+		}
+		if in {
+			return "", ""
+		}
+	}
+
+	return ReasonOutsideAllowedWindow, "Step advancement is paused outside of allowed windows"
+}
+
+// inWindow now가 window의 schedule 시작 시간부터 duration 안에 있는지 확인합니다.
+// now - duration 이후 첫 시작 시간이 now 이전이면 window 안에 있습니다.
+func inWindow(window canaryv1alpha1.TimeWindow, now time.Time) (bool, error) {
+	spec := window.Schedule
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=" + timeZone(window.TimeZone) + " " + spec
+	}
+	sched, err := cronv3.ParseStandard(spec)
+	if err != nil {
+		return false, err
+	}
+
+	return !sched.Next(now.Add(-window.Duration.Duration)).After(now), nil
+}
+
+// isHoliday holidays ConfigMap에 오늘 날짜가 있으면 그 날짜를 반환합니다.
+func isHoliday(ctx context.Context, c client.Reader, canary *canaryv1alpha1.Canary, now time.Time) (string, error) {
+	ref := canary.Spec.Holidays.ConfigMapRef
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = canary.Namespace
+	}
+	// 다른 namespace의 ConfigMap을 읽지 않도록 Canary나 Canary Operator namespace의 ConfigMap만 허용합니다.
+	if namespace != canary.Namespace && namespace != operatorNamespace() {
+		return "", fmt.Errorf("holidays ConfigMap must be in the namespace of the canary or the operator, not %s", namespace)
+	}
+
+	loc, err := time.LoadLocation(timeZone(canary.Spec.Holidays.TimeZone))
+	if err != nil {
+		return "", err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, cm); err != nil {
+		return "", err
+	}
+
+	today := now.In(loc).Format(time.DateOnly)
+	for _, value := range cm.Data {
+		for _, line := range strings.Split(value, "\n") {
+			if strings.TrimSpace(line) == today {
+				return today, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// operatorNamespace Canary Operator가 실행 중인 namespace를 반환합니다. manager Deployment가 POD_NAMESPACE로 설정합니다.
+func operatorNamespace() string {
+	return os.Getenv("POD_NAMESPACE")
+}
+
+// timeZone 비어 있으면 UTC를 반환합니다.
+func timeZone(tz string) string {
+	if tz == "" {
+		return "UTC"
+	}
+
+	return tz
+}
+
+// windowName status에 표시할 window 이름을 반환합니다.
+func windowName(window canaryv1alpha1.TimeWindow, i int) string {
+	if window.Name != "" {
+		return window.Name
+	}
+
+	return fmt.Sprintf("#%d", i)
+}
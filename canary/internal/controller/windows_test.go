@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	canaryv1alpha1 "github.com/k8shuginn/canary-operator/api/v1alpha1"
+)
+
+var _ = Describe("Step windows", func() {
+	ctx := context.Background()
+
+	// 2024-06-03 월요일 10:00 Asia/Seoul (01:00 UTC)
+	now := time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC)
+	businessHours := canaryv1alpha1.TimeWindow{
+		Name:     "business-hours",
+		Schedule: "0 9 * * 1-5",
+		Duration: metav1.Duration{Duration: 8 * time.Hour},
+		TimeZone: "Asia/Seoul",
+	}
+
+	DescribeTable("inWindow",
+		func(window canaryv1alpha1.TimeWindow, at time.Time, expected bool) {
+			in, err := inWindow(window, at)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(in).To(Equal(expected))
+		},
+		Entry("inside the window in the time zone", businessHours, now, true),
+		Entry("after the window ends", businessHours, now.Add(8*time.Hour), false),
+		Entry("on the weekend", businessHours, now.Add(-48*time.Hour), false),
+		Entry("in UTC by default", canaryv1alpha1.TimeWindow{Schedule: "0 9 * * 1-5", Duration: metav1.Duration{Duration: 8 * time.Hour}}, now, false),
+	)
+
+	It("should reject an invalid schedule", func() {
+		_, err := inWindow(canaryv1alpha1.TimeWindow{Schedule: "invalid"}, now)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should block outside of allowed windows and inside blackout windows", func() {
+		canary := &canaryv1alpha1.Canary{Spec: canaryv1alpha1.CanarySpec{AllowedWindows: []canaryv1alpha1.TimeWindow{businessHours}}}
+		reason, _ := windowBlocked(ctx, k8sClient, canary, now)
+		Expect(reason).To(BeEmpty())
+		reason, _ = windowBlocked(ctx, k8sClient, canary, now.Add(10*time.Hour))
+		Expect(reason).To(Equal(ReasonOutsideAllowedWindow))
+
+		canary.Spec.BlackoutWindows = []canaryv1alpha1.TimeWindow{{Name: "lunch", Schedule: "0 12 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Asia/Seoul"}}
+		reason, message := windowBlocked(ctx, k8sClient, canary, now.Add(2*time.Hour+30*time.Minute))
+		Expect(reason).To(Equal(ReasonBlackoutWindow))
+		Expect(message).To(ContainSubstring("lunch"))
+	})
+
+	It("should block on holidays of the shared ConfigMap", func() {
+		Expect(os.Setenv("POD_NAMESPACE", "default")).To(Succeed())
+		DeferCleanup(os.Unsetenv, "POD_NAMESPACE")
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "holidays"},
+			Data:       map[string]string{"2024": "2024-06-03\n2024-12-25\n"},
+		}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cm)).To(Succeed())
+		})
+
+		canary := &canaryv1alpha1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other"},
+			Spec: canaryv1alpha1.CanarySpec{Holidays: &canaryv1alpha1.HolidaysSpec{
+				ConfigMapRef: canaryv1alpha1.ConfigMapReference{Namespace: "default", Name: "holidays"},
+				TimeZone:     "Asia/Seoul",
+			}},
+		}
+		reason, _ := windowBlocked(ctx, k8sClient, canary, now)
+		Expect(reason).To(Equal(ReasonHoliday))
+		// 2024-06-02 23:00 Asia/Seoul
+		reason, _ = windowBlocked(ctx, k8sClient, canary, now.Add(-11*time.Hour))
+		Expect(reason).To(BeEmpty())
+	})
+
+	It("should not read holidays from another namespace", func() {
+		canary := &canaryv1alpha1.Canary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other"},
+			Spec: canaryv1alpha1.CanarySpec{Holidays: &canaryv1alpha1.HolidaysSpec{
+				ConfigMapRef: canaryv1alpha1.ConfigMapReference{Namespace: "kube-system", Name: "holidays"},
+			}},
+		}
+		reason, message := windowBlocked(ctx, k8sClient, canary, now)
+		Expect(reason).To(Equal(ReasonInvalidWindow))
+		Expect(message).To(ContainSubstring("namespace of the canary or the operator"))
+	})
+
+	It("should record the reason instead of advancing the step", func() {
+		spec := testCanarySpec()
+		spec.BlackoutWindows = []canaryv1alpha1.TimeWindow{{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}}}
+		canary := createTestCanary(ctx, metav1.ObjectMeta{Name: "window-blocked"}, spec,
+			&canaryv1alpha1.CanaryStatus{State: canaryv1alpha1.PhaseProgressing})
+
+		ok, err := advanceStep(ctx, k8sClient, k8sClient, nil, canary, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(canary.Status.CurrentStep).To(BeZero())
+		Expect(meta.IsStatusConditionTrue(canary.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+
+		canary.Spec.BlackoutWindows = nil
+		ok, err = advanceStep(ctx, k8sClient, k8sClient, nil, canary, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(canary.Status.CurrentStep).To(Equal(int32(1)))
+		Expect(meta.IsStatusConditionFalse(canary.Status.Conditions, ConditionStepBlocked)).To(BeTrue())
+	})
+})
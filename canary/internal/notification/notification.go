@@ -0,0 +1,256 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Event Canary lifecycle 이벤트입니다.
+type Event string
+
+const (
+	EventStarted    Event = "started"
+	EventPaused     Event = "paused"
+	EventCompleted  Event = "completed"
+	EventRolledBack Event = "rolledback"
+)
+
+// DefaultTemplate 이벤트별 template이 없을 때 사용하는 메시지 template입니다.
+const DefaultTemplate = "[{{.Namespace}}/{{.Name}}] Canary {{.Event}} (step {{.Step}}, old {{.OldReplicas}}, new {{.NewReplicas}}) {{.Message}}"
+
+// Message 전송할 알림 정보입니다.
+type Message struct {
+	Event       Event
+	Namespace   string
+	Name        string
+	Step        int32
+	OldReplicas int32
+	NewReplicas int32
+	State       string
+	Message     string
+
+	// SecretRef webhook URL을 가지고 있는 Secret 이름입니다. (Canary와 동일한 namespace)
+	SecretRef string
+	// Template 메시지 template입니다. 비어있으면 DefaultTemplate을 사용합니다.
+	Template string
+}
+
+// Options Notifier 설정입니다.
+type Options struct {
+	// Workers 동시에 알림을 전송하는 worker 수
+	Workers int
+	// QueueSize 전송 대기열 크기, 대기열이 가득 차면 알림은 버려집니다.
+	QueueSize int
+	// Retries 전송 실패 시 재시도 횟수
+	Retries int
+	// Backoff 첫 재시도 대기 시간, 재시도마다 2배씩 증가합니다.
+	Backoff time.Duration
+	// Timeout webhook 요청 timeout
+	Timeout time.Duration
+}
+
+// Notifier Slack, Microsoft Teams incoming webhook으로 알림을 비동기 전송합니다.
+type Notifier struct {
+	reader client.Reader
+	http   *http.Client
+	queue  chan Message
+	opts   Options
+}
+
+// NewNotifier Notifier를 생성합니다. manager에 Runnable로 등록하여 worker를 실행합니다.
+func NewNotifier(reader client.Reader, opts Options) *Notifier {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	return &Notifier{
+		reader: reader,
+		http:   &http.Client{Timeout: opts.Timeout},
+		queue:  make(chan Message, opts.QueueSize),
+		opts:   opts,
+	}
+}
+
+// Notify 알림을 전송 대기열에 추가합니다. 호출자는 전송 완료를 기다리지 않습니다.
+func (n *Notifier) Notify(ctx context.Context, msg Message) {
+	if n == nil {
+		return
+	}
+
+	select {
+	case n.queue <- msg:
+	default:
+		log.FromContext(ctx).Info("[Notification] Queue is full, dropping notification",
+			"namespace", msg.Namespace, "name", msg.Name, "event", msg.Event)
+	}
+}
+
+// Start worker를 실행하고 ctx가 종료될 때까지 대기합니다.
+func (n *Notifier) Start(ctx context.Context) error {
+	for i := 0; i < n.opts.Workers; i++ {
+		go n.worker(ctx)
+	}
+	<-ctx.Done()
+
+	return nil
+}
+
+// NeedLeaderElection leader만 알림을 전송합니다.
+func (n *Notifier) NeedLeaderElection() bool {
+	return true
+}
+
+func (n *Notifier) worker(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-n.queue:
+			if err := n.send(ctx, msg); err != nil {
+				logger.Error(err, "[Notification] Failed to send notification",
+					"namespace", msg.Namespace, "name", msg.Name, "event", msg.Event)
+			}
+		}
+	}
+}
+
+// send Secret의 모든 webhook URL로 메시지를 전송합니다.
+func (n *Notifier) send(ctx context.Context, msg Message) error {
+	urls, err := n.webhookURLs(ctx, msg.Namespace, msg.SecretRef)
+	if err != nil {
+		return err
+	}
+
+	text, err := Render(msg)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, url := range urls {
+		if err := n.post(ctx, url, body); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send to %d of %d webhooks: %s", len(errs), len(urls), strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// post webhook URL로 요청을 전송하며 실패 시 backoff 후 재시도합니다.
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	backoff := n.opts.Backoff
+	var err error
+	for attempt := 0; attempt <= n.opts.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		if retry, err = n.postOnce(ctx, url, body); err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// postOnce webhook 요청을 한 번 전송합니다. 재시도 가능한 에러인 경우 true를 반환합니다.
+func (n *Notifier) postOnce(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// webhookURLs Secret의 모든 값을 webhook URL로 반환합니다.
+func (n *Notifier) webhookURLs(ctx context.Context, namespace, name string) ([]string, error) {
+	secret := &corev1.Secret{}
+	if err := n.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get notification secret %s/%s: %w", namespace, name, err)
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	urls := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if url := strings.TrimSpace(string(secret.Data[key])); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls, nil
+}
+
+// Render 메시지 template을 적용한 알림 내용을 반환합니다.
+func Render(msg Message) (string, error) {
+	tmpl := msg.Template
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+
+	t, err := template.New(string(msg.Event)).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse notification template: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, msg); err != nil {
+		return "", fmt.Errorf("failed to render notification template: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
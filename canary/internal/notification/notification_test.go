@@ -0,0 +1,144 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// webhookServer 수신한 알림을 기록하는 테스트용 incoming webhook 서버입니다.
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	texts    []string
+	failures int32
+	calls    int32
+}
+
+func newWebhookServer(failures int32) *webhookServer {
+	s := &webhookServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&s.calls, 1) <= s.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		payload := map[string]string{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.texts = append(s.texts, payload["text"])
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return s
+}
+
+func (s *webhookServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.texts...)
+}
+
+var _ = Describe("Notifier", func() {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		slack  *webhookServer
+		teams  *webhookServer
+	)
+
+	startNotifier := func(failures int32, retries int) *Notifier {
+		slack = newWebhookServer(failures)
+		teams = newWebhookServer(0)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "canary-webhooks"},
+			Data: map[string][]byte{
+				"slack": []byte(slack.URL),
+				"teams": []byte(teams.URL),
+			},
+		}
+		reader := fake.NewClientBuilder().WithObjects(secret).Build()
+
+		n := NewNotifier(reader, Options{Retries: retries, Backoff: 10 * time.Millisecond})
+		go func() {
+			defer GinkgoRecover()
+			Expect(n.Start(ctx)).To(Succeed())
+		}()
+
+		return n
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+		slack.Close()
+		teams.Close()
+	})
+
+	It("should send the rendered message to every webhook in the secret", func() {
+		n := startNotifier(0, 0)
+		n.Notify(ctx, Message{
+			Event:       EventStarted,
+			Namespace:   "default",
+			Name:        "canary-sample",
+			Step:        1,
+			OldReplicas: 8,
+			NewReplicas: 2,
+			SecretRef:   "canary-webhooks",
+			Template:    "{{.Name}} {{.Event}} at step {{.Step}}",
+		})
+
+		Eventually(slack.received).Should(Equal([]string{"canary-sample started at step 1"}))
+		Eventually(teams.received).Should(Equal([]string{"canary-sample started at step 1"}))
+	})
+
+	It("should retry when the webhook fails", func() {
+		n := startNotifier(2, 3)
+		n.Notify(ctx, Message{Event: EventRolledBack, Namespace: "default", Name: "canary-sample", SecretRef: "canary-webhooks"})
+
+		Eventually(slack.received).Should(HaveLen(1))
+		Expect(atomic.LoadInt32(&slack.calls)).To(Equal(int32(3)))
+		Expect(slack.received()[0]).To(HavePrefix("[default/canary-sample] Canary rolledback"))
+	})
+
+	It("should give up after the retries are exhausted", func() {
+		n := startNotifier(10, 1)
+		n.Notify(ctx, Message{Event: EventPaused, Namespace: "default", Name: "canary-sample", SecretRef: "canary-webhooks"})
+
+		Eventually(teams.received).Should(HaveLen(1))
+		Consistently(func() int32 { return atomic.LoadInt32(&slack.calls) }, 200*time.Millisecond).Should(Equal(int32(2)))
+		Expect(slack.received()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Render", func() {
+	It("should use the default template", func() {
+		text, err := Render(Message{Event: EventCompleted, Namespace: "default", Name: "canary-sample", Step: 5, NewReplicas: 10, Message: "Canary is complete"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal("[default/canary-sample] Canary completed (step 5, old 0, new 10) Canary is complete"))
+	})
+
+	It("should fail on an invalid template", func() {
+		_, err := Render(Message{Template: "{{.Name"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracerName  = "github.com/k8shuginn/canary-operator"
+	ServiceName = "canary-operator"
+)
+
+// Options OTLP tracing 설정입니다.
+type Options struct {
+	// Endpoint OTLP gRPC collector 주소입니다. 비어있으면 tracing을 사용하지 않습니다.
+	Endpoint string
+	// Insecure TLS 없이 collector에 연결합니다.
+	Insecure bool
+	// SampleRatio 0~1 사이의 trace sampling 비율입니다.
+	SampleRatio float64
+}
+
+// Setup OTLP exporter를 사용하는 TracerProvider를 전역으로 등록합니다.
+// Endpoint가 비어있으면 아무것도 하지 않으며, 반환된 함수로 provider를 종료합니다.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tp := NewProvider(sdktrace.NewBatchSpanProcessor(exporter), opts.SampleRatio)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
+
+// NewProvider span processor와 sampling 비율로 TracerProvider를 생성합니다.
+// 테스트에서는 in-memory exporter의 processor를 전달하여 사용합니다.
+func NewProvider(processor sdktrace.SpanProcessor, sampleRatio float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+}
+
+// Enabled Setup이나 테스트에서 SDK TracerProvider가 등록되어 tracing을 사용하는지 확인합니다.
+func Enabled() bool {
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	return ok
+}
+
+// Tracer operator에서 사용하는 Tracer를 반환합니다.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start Canary run의 trace에 연결된 span을 시작합니다.
+// traceID, spanID가 유효하지 않으면 ctx의 span을 parent로 사용합니다.
+func Start(
+	ctx context.Context,
+	traceID, spanID string,
+	spanName string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if parent, ok := RunSpanContext(traceID, spanID); ok && !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+
+	return Tracer().Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// StartRun Canary run의 root span을 생성하고 status에 저장할 trace ID, span ID를 반환합니다.
+// span이 sampling 되지 않은 경우 빈 문자열을 반환합니다.
+func StartRun(ctx context.Context, attrs ...attribute.KeyValue) (traceID, spanID string) {
+	_, span := Tracer().Start(ctx, "CanaryRun", trace.WithNewRoot(), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return "", ""
+	}
+
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// RunSpanContext status에 저장된 trace ID, span ID로 remote span context를 구성합니다.
+func RunSpanContext(traceID, spanID string) (trace.SpanContext, bool) {
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tid,
+		SpanID:     sid,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}), true
+}
+
+// CanaryAttributes Canary를 식별하는 span attribute를 반환합니다.
+func CanaryAttributes(namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("canary.namespace", namespace),
+		attribute.String("canary.name", name),
+	}
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Tracing", func() {
+	var exporter *tracetest.InMemoryExporter
+	var provider *sdktrace.TracerProvider
+
+	BeforeEach(func() {
+		exporter = tracetest.NewInMemoryExporter()
+		provider = NewProvider(sdktrace.NewSimpleSpanProcessor(exporter), 1.0)
+		otel.SetTracerProvider(provider)
+	})
+
+	AfterEach(func() {
+		Expect(provider.Shutdown(context.Background())).To(Succeed())
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	It("should be enabled only with an SDK provider", func() {
+		Expect(Enabled()).To(BeTrue())
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		Expect(Enabled()).To(BeFalse())
+	})
+
+	It("should link spans of a canary run under one trace ID", func() {
+		ctx := context.Background()
+		traceID, spanID := StartRun(ctx, CanaryAttributes("default", "canary-sample")...)
+		Expect(traceID).NotTo(BeEmpty())
+		Expect(spanID).NotTo(BeEmpty())
+
+		By("starting a reconcile span and a nested span")
+		reconcileCtx, reconcileSpan := Start(ctx, traceID, spanID, "Reconcile")
+		_, syncSpan := Tracer().Start(reconcileCtx, "syncDeployments")
+		syncSpan.End()
+		reconcileSpan.End()
+
+		By("starting a cron span in a separate context")
+		_, cronSpan := Start(context.Background(), traceID, spanID, "CronJob.Run")
+		cronSpan.End()
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(4))
+		for _, span := range spans {
+			Expect(span.SpanContext.TraceID().String()).To(Equal(traceID))
+		}
+
+		byName := map[string]tracetest.SpanStub{}
+		for _, span := range spans {
+			byName[span.Name] = span
+		}
+		Expect(byName["Reconcile"].Parent.SpanID().String()).To(Equal(spanID))
+		Expect(byName["CronJob.Run"].Parent.SpanID().String()).To(Equal(spanID))
+		Expect(byName["syncDeployments"].Parent.SpanID()).To(Equal(byName["Reconcile"].SpanContext.SpanID()))
+	})
+
+	It("should start a new root span without a stored run", func() {
+		_, span := Start(context.Background(), "", "", "Reconcile")
+		span.End()
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Parent.IsValid()).To(BeFalse())
+	})
+
+	It("should not return IDs for an unsampled run", func() {
+		otel.SetTracerProvider(NewProvider(sdktrace.NewSimpleSpanProcessor(exporter), 0))
+
+		traceID, spanID := StartRun(context.Background())
+		Expect(traceID).To(BeEmpty())
+		Expect(spanID).To(BeEmpty())
+	})
+
+	It("should not set up a provider without an endpoint", func() {
+		shutdown, err := Setup(context.Background(), Options{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shutdown(context.Background())).To(Succeed())
+		Expect(otel.GetTracerProvider()).To(Equal(provider))
+	})
+})